@@ -32,7 +32,7 @@ func GetUserOneV2(c *gin.Context) {
 
 // UpdateUsersV2 使用通用处理器更新
 func UpdateUsersV2(c *gin.Context) {
-	dbkit.GenericUpdateHandler[entity.User, request.UserFilters](config.DB)(c)
+	dbkit.GenericUpdateHandler[entity.User, request.UserFilters, request.UserUpdates](config.DB)(c)
 }
 
 // DeleteUsersV2 使用通用处理器删除
@@ -42,11 +42,14 @@ func DeleteUsersV2(c *gin.Context) {
 
 // ============ 新增功能示例 ============
 
-// CreateUserV2 使用通用处理器创建（自动生成ID）
+// userID 创建前为用户生成32位UUID的钩子
+var userID = dbkit.WithGeneratedID(func(u *entity.User, id string) {
+	u.ID = id
+})
+
+// CreateUserV2 使用通用处理器创建（通过钩子自动生成ID）
 func CreateUserV2(c *gin.Context) {
-	dbkit.GenericCreateWithIDHandler[entity.User](config.DB, func(u *entity.User, id string) {
-		u.ID = id
-	})(c)
+	dbkit.GenericCreateHandler[entity.User](config.DB, userID)(c)
 }
 
 // CreateUserNative 原生GORM实现示例（完全不使用dbkit，对比用）
@@ -85,59 +88,16 @@ func CreateUserNative(c *gin.Context) {
 	})
 }
 
-// QueryUsersWithOr OR条件查询示例
+// QueryUsersWithOr OR条件查询示例（UserFilters 嵌入了 dbkit.Logic，支持嵌套 and/or/not）
 func QueryUsersWithOr(c *gin.Context) {
-	var req struct {
-		Page    *dbkit.Page `json:"page"`
-		Filters struct {
-			// OR 条件：age >= 30 OR name LIKE '%admin%'
-			Or []struct {
-				Age  *int    `json:"age" filter:"gte"`
-				Name *string `json:"name" filter:"like"`
-			} `json:"or"`
-		} `json:"filters"`
-		Orders struct {
-			Age *string `json:"age"`
-			ID  *string `json:"id"`
-		} `json:"orders"`
-	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dbkit.Error("Invalid request: "+err.Error()))
-		return
-	}
-
-	var users []entity.User
-	var model entity.User
-
-	qb := dbkit.NewQueryBuilder(config.DB.Model(&model))
-
-	// 应用 OR 条件
-	if len(req.Filters.Or) > 0 {
-		orConditions := make([]interface{}, len(req.Filters.Or))
-		for i, cond := range req.Filters.Or {
-			orConditions[i] = cond
-		}
-		qb.ApplyOrConditions(orConditions)
-	}
-
-	qb.ApplyOrders(req.Orders)
-	qb.ApplyPagination(req.Page)
-
-	count, err := qb.QueryWithCount(&users)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, dbkit.Error(err.Error()))
-		return
-	}
-
-	c.JSON(http.StatusOK, dbkit.SuccessWithPage(users, req.Page, count))
+	dbkit.GenericQueryHandler[entity.User, request.UserFilters, request.UserOrders](config.DB)(c)
 }
 
 // GetUserStats 获取用户统计信息
 func GetUserStats(c *gin.Context) {
 	var req request.UserQueryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dbkit.Error("Invalid request: "+err.Error()))
+	if err := dbkit.BindJSON(c, &req); err != nil {
+		dbkit.RespondError(c, err)
 		return
 	}
 
@@ -149,49 +109,29 @@ func GetUserStats(c *gin.Context) {
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dbkit.Error(err.Error()))
+		dbkit.RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, dbkit.Success(stats))
 }
 
-// BatchCreateUsers 批量创建用户
+// BatchCreateUsers 批量创建用户（每批100条，通过钩子为每个用户生成ID）
 func BatchCreateUsers(c *gin.Context) {
-	var users []entity.User
-	if err := c.ShouldBindJSON(&users); err != nil {
-		c.JSON(http.StatusBadRequest, dbkit.Error("Invalid request: "+err.Error()))
-		return
-	}
-
-	// 为每个用户生成ID
-	for i := range users {
-		users[i].ID = strings.ReplaceAll(uuid.New().String(), "-", "")
-	}
-
-	// 批量创建，每批100条
-	if err := dbkit.BatchCreate(config.DB, users, 100); err != nil {
-		c.JSON(http.StatusInternalServerError, dbkit.Error(err.Error()))
-		return
-	}
-
-	c.JSON(http.StatusOK, dbkit.Success(map[string]interface{}{
-		"created": len(users),
-		"users":   users,
-	}))
+	dbkit.GenericBatchCreateHandler[entity.User](config.DB, 100, userID)(c)
 }
 
 // BatchUpdateUsers 批量更新用户（不同ID不同值）
 func BatchUpdateUsers(c *gin.Context) {
 	var items []dbkit.BatchUpdateItem
-	if err := c.ShouldBindJSON(&items); err != nil {
-		c.JSON(http.StatusBadRequest, dbkit.Error("Invalid request: "+err.Error()))
+	if err := dbkit.BindJSON(c, &items); err != nil {
+		dbkit.RespondError(c, err)
 		return
 	}
 
 	affected, err := dbkit.BatchUpdateByID[entity.User](config.DB, items)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dbkit.Error(err.Error()))
+		dbkit.RespondError(c, err)
 		return
 	}
 
@@ -205,8 +145,8 @@ func BatchDeleteUsers(c *gin.Context) {
 	var req struct {
 		IDs []string `json:"ids" binding:"required"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dbkit.Error("Invalid request: "+err.Error()))
+	if err := dbkit.BindJSON(c, &req); err != nil {
+		dbkit.RespondError(c, err)
 		return
 	}
 
@@ -217,7 +157,7 @@ func BatchDeleteUsers(c *gin.Context) {
 
 	affected, err := dbkit.BatchDelete[entity.User](config.DB, ids)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dbkit.Error(err.Error()))
+		dbkit.RespondError(c, err)
 		return
 	}
 
@@ -0,0 +1,188 @@
+package dbkit
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAggregate = errors.New("invalid aggregate")
+
+// Aggregate 聚合表达式
+type Aggregate struct {
+	Func   string `json:"func"`             // count, count_distinct, sum, avg, min, max, variance, stddev
+	Column string `json:"column,omitempty"` // count 时可省略或为 *
+	Alias  string `json:"alias,omitempty"`
+}
+
+// HavingCondition 分组后的过滤条件：聚合结果与值比较
+type HavingCondition struct {
+	Aggregate
+	Op    string      `json:"op"` // eq, ne, gt, gte, lt, lte
+	Value interface{} `json:"value"`
+}
+
+var aggregateFuncs = map[string]string{
+	"count":          "COUNT(%s)",
+	"count_distinct": "COUNT(DISTINCT %s)",
+	"sum":            "SUM(%s)",
+	"avg":            "AVG(%s)",
+	"min":            "MIN(%s)",
+	"max":            "MAX(%s)",
+	"variance":       "VAR_POP(%s)",
+	"stddev":         "STDDEV_POP(%s)",
+}
+
+var compareOperators = map[string]string{
+	"eq":  "=",
+	"ne":  "!=",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+var (
+	aggregateRegexp = regexp.MustCompile(`(?i)^\s*(count|sum|avg|min|max|variance|stddev)\s*\(\s*(distinct\s+)?(\*|[A-Za-z_][A-Za-z0-9_]*)\s*\)\s*(?:as\s+([A-Za-z_][A-Za-z0-9_]*))?\s*$`)
+	havingRegexp    = regexp.MustCompile(`^\s*(.+?)\s*(>=|<=|!=|<>|=|>|<)\s*(-?[0-9]+(?:\.[0-9]+)?)\s*$`)
+)
+
+// ParseAggregate 解析 "COUNT(*) AS count"、"SUM(age)" 形式的表达式
+func ParseAggregate(expr string) (*Aggregate, error) {
+	m := aggregateRegexp.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAggregate, expr)
+	}
+
+	fn := strings.ToLower(m[1])
+	if m[2] != "" {
+		if fn != "count" || m[3] == "*" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAggregate, expr)
+		}
+		fn = "count_distinct"
+	}
+
+	column := m[3]
+	if column == "*" {
+		if fn != "count" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAggregate, expr)
+		}
+		column = ""
+	}
+
+	return &Aggregate{Func: fn, Column: column, Alias: m[4]}, nil
+}
+
+// ParseHaving 解析 "COUNT(*) > 1" 形式的条件，右侧只允许数字
+func ParseHaving(expr string) (*HavingCondition, error) {
+	m := havingRegexp.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAggregate, expr)
+	}
+
+	agg, err := ParseAggregate(m[1])
+	if err != nil || agg.Alias != "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAggregate, expr)
+	}
+
+	value, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAggregate, expr)
+	}
+
+	op := map[string]string{"=": "eq", "!=": "ne", "<>": "ne", ">": "gt", ">=": "gte", "<": "lt", "<=": "lte"}[m[2]]
+	return &HavingCondition{Aggregate: *agg, Op: op, Value: value}, nil
+}
+
+// aggregateExpr 生成聚合 SQL（不含别名），列名经 schema 校验
+func (qb *QueryBuilder) aggregateExpr(a Aggregate) (string, error) {
+	format, ok := aggregateFuncs[a.Func]
+	if !ok {
+		return "", fmt.Errorf("%w: unsupported function %q", ErrInvalidAggregate, a.Func)
+	}
+
+	if a.Column == "" || a.Column == "*" {
+		if a.Func != "count" {
+			return "", fmt.Errorf("%w: %s requires a column", ErrInvalidAggregate, a.Func)
+		}
+		return "COUNT(*)", nil
+	}
+
+	column, err := qb.quoteColumn(a.Column)
+	if err != nil {
+		return "", err
+	}
+
+	// SQLite 没有方差与标准差函数，方差按 E(x²) - E(x)² 计算
+	if qb.db.Dialector.Name() == "sqlite" {
+		switch a.Func {
+		case "variance":
+			return fmt.Sprintf("(AVG(%[1]s * %[1]s) - AVG(%[1]s) * AVG(%[1]s))", column), nil
+		case "stddev":
+			return "", fmt.Errorf("%w: stddev is not supported by sqlite", ErrInvalidAggregate)
+		}
+	}
+	return fmt.Sprintf(format, column), nil
+}
+
+// ApplySelectExpr 选择列或聚合表达式（如 "department"、"COUNT(*) AS count"），
+// 用于接收客户端输入，无法解析的表达式记录错误
+func (qb *QueryBuilder) ApplySelectExpr(exprs ...string) *QueryBuilder {
+	if len(exprs) == 0 {
+		return qb
+	}
+
+	selects := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		if !strings.Contains(expr, "(") {
+			column, err := qb.quoteColumn(strings.TrimSpace(expr))
+			if err != nil {
+				qb.db.AddError(err)
+				return qb
+			}
+			selects = append(selects, column)
+			continue
+		}
+
+		agg, err := ParseAggregate(expr)
+		if err != nil {
+			qb.db.AddError(err)
+			return qb
+		}
+
+		sql, err := qb.aggregateExpr(*agg)
+		if err != nil {
+			qb.db.AddError(err)
+			return qb
+		}
+		if agg.Alias != "" {
+			sql += " AS " + qb.quote(agg.Alias)
+		}
+		selects = append(selects, sql)
+	}
+
+	qb.db = qb.db.Select(selects)
+	return qb
+}
+
+// ApplyHavingConditions 追加结构化的 HAVING 条件
+func (qb *QueryBuilder) ApplyHavingConditions(conds ...HavingCondition) *QueryBuilder {
+	for _, cond := range conds {
+		sql, err := qb.aggregateExpr(cond.Aggregate)
+		if err != nil {
+			qb.db.AddError(err)
+			return qb
+		}
+
+		op, ok := compareOperators[cond.Op]
+		if !ok {
+			qb.db.AddError(fmt.Errorf("%w: unsupported having operator %q", ErrInvalidAggregate, cond.Op))
+			return qb
+		}
+
+		qb.db = qb.db.Having(fmt.Sprintf("%s %s ?", sql, op), cond.Value)
+	}
+	return qb
+}
@@ -0,0 +1,324 @@
+package dbkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 审计日志的操作类型
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// auditSettingKey gorm.DB 上开启审计的设置项
+const auditSettingKey = "dbkit:audit"
+
+// requestIDHeader 请求 ID 的请求头与响应头
+const requestIDHeader = "X-Request-ID"
+
+// AuditLog 审计日志，每条记录对应一个实体的一次变更，Before/After 为变更前后的 JSON 快照（创建时 Before 为空，永久删除时 After 为空）
+type AuditLog struct {
+	ID        uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Entity    string          `gorm:"type:varchar(64);index:idx_audit_log_entity" json:"entity"` // 表名
+	EntityID  string          `gorm:"type:varchar(64);index:idx_audit_log_entity" json:"entity_id"`
+	Action    string          `gorm:"type:varchar(16)" json:"action"`
+	Before    json.RawMessage `gorm:"type:json" json:"before"`
+	After     json.RawMessage `gorm:"type:json" json:"after"`
+	Actor     string          `gorm:"type:varchar(64)" json:"actor"`
+	RequestID string          `gorm:"type:varchar(64)" json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// AuditFilters 审计日志的过滤条件，可配合 GenericQueryHandler[AuditLog, AuditFilters, AuditOrders] 浏览全部日志
+type AuditFilters struct {
+	Entity    *string `json:"entity"`
+	EntityID  *string `json:"entity_id"`
+	Action    *string `json:"action"`
+	Actor     *string `json:"actor"`
+	RequestID *string `json:"request_id"`
+}
+
+// AuditOrders 审计日志的排序
+type AuditOrders struct {
+	ID        *string `json:"id"`
+	CreatedAt *string `json:"created_at"`
+}
+
+// EnableAudit 返回开启审计的 DB：Create、Update、Delete 及按主键、批量的变更函数在同一事务中写入审计日志。
+//
+//	db = dbkit.EnableAudit(db)
+func EnableAudit(db *gorm.DB) *gorm.DB {
+	return db.Set(auditSettingKey, true).Session(&gorm.Session{})
+}
+
+// auditEnabled DB 是否开启了审计
+func auditEnabled(db *gorm.DB) bool {
+	enabled, _ := db.Get(auditSettingKey)
+	b, _ := enabled.(bool)
+	return b
+}
+
+// withoutAudit 审计事务内关闭审计，避免变更函数重复记录
+func withoutAudit(tx *gorm.DB) *gorm.DB {
+	return tx.Set(auditSettingKey, false).Session(&gorm.Session{})
+}
+
+type auditContextKey struct{}
+
+type auditInfo struct {
+	actor     string
+	requestID string
+}
+
+// WithAuditInfo 在 context 中记录操作人与请求 ID，通过 db.WithContext(ctx) 传给变更函数
+func WithAuditInfo(ctx context.Context, actor, requestID string) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, auditInfo{actor: actor, requestID: requestID})
+}
+
+func auditInfoFrom(ctx context.Context) auditInfo {
+	if ctx == nil {
+		return auditInfo{}
+	}
+	info, _ := ctx.Value(auditContextKey{}).(auditInfo)
+	return info
+}
+
+// AuditMiddleware 将操作人（由 actor 从 gin.Context 中取出，如登录用户）与请求 ID 写入请求的 context，
+// 请求 ID 取自 X-Request-ID 请求头，没有时生成并写入响应头
+func AuditMiddleware(actor func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" {
+			requestID = generateUUID32()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		var who string
+		if actor != nil {
+			who = actor(c)
+		}
+		c.Request = c.Request.WithContext(WithAuditInfo(c.Request.Context(), who, requestID))
+		c.Next()
+	}
+}
+
+// auditChanges 在事务中执行变更 fn，记录 scope 选出的记录在变更前后的快照
+func auditChanges[T any](db *gorm.DB, action string, scope func(*gorm.DB) *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		tx = withoutAudit(tx)
+
+		var before []T
+		if err := scope(tx.Model(new(T))).Find(&before).Error; err != nil {
+			return err
+		}
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		var after []T
+		if len(before) > 0 {
+			ids, err := primaryKeys(tx, before)
+			if err != nil {
+				return err
+			}
+			// 软删除后的记录也需要读出
+			if err := idScope(ids...)(tx.Unscoped().Model(new(T))).Find(&after).Error; err != nil {
+				return err
+			}
+		}
+
+		return writeAuditLogs(tx, action, before, after)
+	})
+}
+
+// auditCreate 在事务中执行创建 fn，记录 created 返回的新记录
+func auditCreate[T any](db *gorm.DB, fn func(tx *gorm.DB) error, created func() []T) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		tx = withoutAudit(tx)
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return writeAuditLogs(tx, AuditCreate, nil, created())
+	})
+}
+
+// idScope 按主键选出记录
+func idScope(ids ...interface{}) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		qb := NewQueryBuilder(tx)
+		pk, err := qb.primaryKey()
+		if err != nil {
+			_ = tx.AddError(err)
+			return tx
+		}
+		return tx.Where(qb.quote(pk.DBName)+" IN ?", ids)
+	}
+}
+
+// filterScope 按过滤结构体选出记录
+func filterScope(filters interface{}) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return NewQueryBuilder(tx).ApplyFilters(filters).GetDB()
+	}
+}
+
+// primaryKeys 记录的主键值
+func primaryKeys[T any](db *gorm.DB, rows []T) ([]interface{}, error) {
+	sch, err := parseSchema(db, new(T))
+	if err != nil {
+		return nil, err
+	}
+	if sch.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%s has no primary key", sch.Name)
+	}
+
+	ids := make([]interface{}, len(rows))
+	for i := range rows {
+		ids[i], _ = sch.PrioritizedPrimaryField.ValueOf(db.Statement.Context, reflect.ValueOf(&rows[i]).Elem())
+	}
+	return ids, nil
+}
+
+// writeAuditLogs 按主键配对 before 与 after，内容未变化的记录不写入
+func writeAuditLogs[T any](tx *gorm.DB, action string, before, after []T) error {
+	sch, err := parseSchema(tx, new(T))
+	if err != nil {
+		return err
+	}
+	beforeIDs, err := primaryKeys(tx, before)
+	if err != nil {
+		return err
+	}
+	afterIDs, err := primaryKeys(tx, after)
+	if err != nil {
+		return err
+	}
+
+	snapshots := make(map[string]json.RawMessage, len(after))
+	for i := range after {
+		raw, err := json.Marshal(after[i])
+		if err != nil {
+			return err
+		}
+		snapshots[fmt.Sprint(afterIDs[i])] = raw
+	}
+
+	info := auditInfoFrom(tx.Statement.Context)
+	entry := func(id string, before, after json.RawMessage) AuditLog {
+		return AuditLog{
+			Entity: sch.Table, EntityID: id, Action: action, Before: before, After: after,
+			Actor: info.actor, RequestID: info.requestID,
+		}
+	}
+
+	var logs []AuditLog
+	seen := make(map[string]bool, len(before))
+	for i := range before {
+		id := fmt.Sprint(beforeIDs[i])
+		seen[id] = true
+		raw, err := json.Marshal(before[i])
+		if err != nil {
+			return err
+		}
+		snapshot, ok := snapshots[id]
+		if ok && string(snapshot) == string(raw) {
+			continue
+		}
+		logs = append(logs, entry(id, raw, snapshot))
+	}
+	for i := range after {
+		id := fmt.Sprint(afterIDs[i])
+		if !seen[id] {
+			logs = append(logs, entry(id, nil, snapshots[id]))
+		}
+	}
+
+	if len(logs) == 0 {
+		return nil
+	}
+	return tx.Create(&logs).Error
+}
+
+// GenericAuditHistoryHandler 记录的变更历史（GET /:id/history），默认按时间倒序分页，
+// 支持 page、page_size、sort 与 action 等查询串参数。查询前执行 T 的 BeforeQuery 钩子
+func GenericAuditHistoryHandler[T any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	table := ""
+	if sch, err := parseSchema(db, new(T)); err == nil {
+		table = sch.Table
+	}
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		perm, err := policy.CanQuery(c)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		id := c.Param("id")
+		if err := hooks.beforeQuery(c, byID(id)); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		// 审计日志不区分租户与策略的过滤条件，此时只允许查看范围内（包括已软删除）的记录
+		if o.tenant != nil || (perm != nil && len(perm.Scope) > 0) {
+			scoped := restrict[T](scoped, perm)
+			if _, err := GetByID[T](scoped.Unscoped(), id); err != nil {
+				o.respondError(c, err)
+				return
+			}
+		}
+
+		var req BaseQueryRequest[AuditFilters, AuditOrders]
+		if err := BindQueryString(c.Request.URL.Query(), &req); err != nil {
+			o.respondError(c, err)
+			return
+		}
+		req.Filters.Entity = &table
+		req.Filters.EntityID = &id
+		if req.Orders == (AuditOrders{}) {
+			desc := "desc"
+			req.Orders.ID = &desc
+		}
+		if req.Page == nil {
+			req.Page = &Page{PageNum: 1, PageSize: defaultPageSize}
+		}
+		if err := validateQuery(&req, o); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		result, err := QueryPage[AuditLog](scoped, &req, opts...)
+		if err == nil {
+			err = maskAuditLogs(result.Data, hiddenNames[T](scoped, perm))
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		writePage(c, o.writer, result, req.Page, fieldMask{})
+	}
+}
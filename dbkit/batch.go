@@ -1,6 +1,8 @@
 package dbkit
 
 import (
+	"fmt"
+
 	"gorm.io/gorm"
 )
 
@@ -14,6 +16,16 @@ func BatchCreate[T any](db *gorm.DB, entities []T, batchSize int) error {
 		batchSize = 100 // 默认批次大小
 	}
 
+	for i := range entities {
+		initVersion(db, &entities[i])
+	}
+	if auditEnabled(db) {
+		return auditCreate(db, func(tx *gorm.DB) error {
+			return tx.CreateInBatches(entities, batchSize).Error
+		}, func() []T {
+			return entities
+		})
+	}
 	return db.CreateInBatches(entities, batchSize).Error
 }
 
@@ -23,11 +35,30 @@ type BatchUpdateItem struct {
 	Updates map[string]interface{} `json:"updates"`
 }
 
+// BatchUpdateRequestItem 批量更新请求项，Updates 为更新结构体（见 UpdatesOf）
+type BatchUpdateRequestItem[U any] struct {
+	ID      interface{} `json:"id"`
+	Updates U           `json:"updates"`
+}
+
 func BatchUpdateByID[T any](db *gorm.DB, items []BatchUpdateItem) (int64, error) {
 	if len(items) == 0 {
 		return 0, nil
 	}
 
+	if auditEnabled(db) {
+		ids := make([]interface{}, len(items))
+		for i, item := range items {
+			ids[i] = item.ID
+		}
+		var affected int64
+		err := auditChanges[T](db, AuditUpdate, idScope(ids...), func(tx *gorm.DB) (err error) {
+			affected, err = BatchUpdateByID[T](tx, items)
+			return err
+		})
+		return affected, err
+	}
+
 	var totalAffected int64
 	var model T
 
@@ -37,10 +68,24 @@ func BatchUpdateByID[T any](db *gorm.DB, items []BatchUpdateItem) (int64, error)
 				continue
 			}
 
-			result := tx.Model(&model).Where("id = ?", item.ID).Updates(item.Updates)
+			qb := NewQueryBuilder(tx.Model(&model))
+			updates, conditioned, err := qb.versionedUpdates(item.Updates)
+			if err != nil {
+				return err
+			}
+
+			if err := qb.whereID(item.ID); err != nil {
+				return err
+			}
+
+			result := qb.GetDB().Updates(updates)
 			if result.Error != nil {
 				return result.Error
 			}
+			// 带版本号的更新没有命中记录时回滚整批
+			if conditioned && result.RowsAffected == 0 {
+				return versionConflict[T](tx, item.ID)
+			}
 			totalAffected += result.RowsAffected
 		}
 		return nil
@@ -55,8 +100,17 @@ func BatchDelete[T any](db *gorm.DB, ids []interface{}) (int64, error) {
 		return 0, nil
 	}
 
+	if auditEnabled(db) {
+		var affected int64
+		err := auditChanges[T](db, AuditDelete, idScope(ids...), func(tx *gorm.DB) (err error) {
+			affected, err = BatchDelete[T](tx, ids)
+			return err
+		})
+		return affected, err
+	}
+
 	var model T
-	result := db.Unscoped().Where("id IN ?", ids).Delete(&model)
+	result := db.Model(&model).Scopes(idScope(ids...)).Delete(&model)
 	return result.RowsAffected, result.Error
 }
 
@@ -80,13 +134,43 @@ func BatchUpdateByFilters[T any](db *gorm.DB, items []BatchUpdateByFilterItem) (
 				continue
 			}
 
+			// 开启审计时逐项记录各自过滤条件命中的记录
+			if auditEnabled(tx) {
+				item := item
+				err := auditChanges[T](tx, AuditUpdate, filterScope(item.Filters), func(inner *gorm.DB) error {
+					affected, err := BatchUpdateByFilters[T](inner, []BatchUpdateByFilterItem{item})
+					totalAffected += affected
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
 			qb := NewQueryBuilder(tx.Model(&model))
 			qb.ApplyFilters(item.Filters)
 
-			result := qb.GetDB().Updates(item.Updates)
+			updates, conditioned, err := qb.versionedUpdates(item.Updates)
+			if err != nil {
+				return err
+			}
+
+			var matched int64
+			if conditioned {
+				if matched, err = matchedCount[T](tx, item.Filters); err != nil {
+					return err
+				}
+			}
+
+			result := qb.GetDB().Updates(updates)
 			if result.Error != nil {
 				return result.Error
 			}
+			// 任一条命中的记录版本不一致时回滚整批
+			if result.RowsAffected < matched {
+				return fmt.Errorf("%w: %d of %d records matching %+v have been modified", ErrVersionConflict, matched-result.RowsAffected, matched, item.Filters)
+			}
 			totalAffected += result.RowsAffected
 		}
 		return nil
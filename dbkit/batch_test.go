@@ -0,0 +1,189 @@
+package dbkit
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// existingUser 查询返回一条记录，COUNT 返回 1
+func existingUser(query string) ([]string, [][]driver.Value) {
+	if strings.Contains(query, "count(*)") {
+		return []string{"count(*)"}, [][]driver.Value{{int64(1)}}
+	}
+	return []string{"id", "name", "age", "tenant_id", "version", "created_at"},
+		[][]driver.Value{{int64(1), "alice", int64(20), int64(1), int64(3), time.Now()}}
+}
+
+func TestUpdateVersionConflict(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.rowsAffected, rec.result = 0, existingUser
+
+	name := "alice"
+	_, err := Update[testUser](db, struct {
+		Name *string `json:"name" filter:"eq"`
+	}{Name: &name}, map[string]interface{}{"age": 21, "version": 2})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("want ErrVersionConflict, got %v", err)
+	}
+
+	sql := rec.last("UPDATE")
+	if !strings.Contains(sql, "`version`=`version` + 1") || !strings.Contains(sql, "`version` = ?") {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+}
+
+func TestUpdatePartialVersionConflict(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.rowsAffected = 2
+	rec.result = func(query string) ([]string, [][]driver.Value) {
+		return []string{"count(*)"}, [][]driver.Value{{int64(3)}}
+	}
+
+	name := "alice"
+	filters := struct {
+		Name *string `json:"name" filter:"eq"`
+	}{Name: &name}
+	_, err := Update[testUser](db, filters, map[string]interface{}{"age": 21, "version": 2})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("want ErrVersionConflict, got %v", err)
+	}
+	if rec.last("ROLLBACK") == "" {
+		t.Fatalf("update was not rolled back: %v", rec.SQL())
+	}
+
+	_, err = BatchUpdateByFilters[testUser](db, []BatchUpdateByFilterItem{
+		{Filters: filters, Updates: map[string]interface{}{"age": 21, "version": 2}},
+	})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("batch: want ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestUpdateRequiresVersion(t *testing.T) {
+	name := "alice"
+	filters := struct {
+		Name *string `json:"name" filter:"eq"`
+	}{Name: &name}
+	updates := func() map[string]interface{} { return map[string]interface{}{"age": 21} }
+
+	tests := map[string]func(db *gorm.DB) error{
+		"Update": func(db *gorm.DB) error {
+			_, err := Update[testUser](db, filters, updates())
+			return err
+		},
+		"UpdateByID": func(db *gorm.DB) error {
+			_, err := UpdateByID[testUser](db, 1, updates())
+			return err
+		},
+		"BatchUpdateByID": func(db *gorm.DB) error {
+			_, err := BatchUpdateByID[testUser](db, []BatchUpdateItem{{ID: 1, Updates: updates()}})
+			return err
+		},
+		"BatchUpdateByFilters": func(db *gorm.DB) error {
+			_, err := BatchUpdateByFilters[testUser](db, []BatchUpdateByFilterItem{{Filters: filters, Updates: updates()}})
+			return err
+		},
+	}
+
+	for name, update := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+			rec.result = existingUser
+
+			err := update(db)
+			if !errors.Is(err, ErrVersionRequired) {
+				t.Fatalf("want ErrVersionRequired, got %v", err)
+			}
+			if status := TranslateError(err).Status; status != http.StatusPreconditionRequired {
+				t.Fatalf("status = %d, want 428", status)
+			}
+			if sql := rec.last("UPDATE"); sql != "" {
+				t.Fatalf("update without version was executed: %s", sql)
+			}
+		})
+	}
+}
+
+func TestBatchUpdateByIDVersionConflict(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.rowsAffected, rec.result = 0, existingUser
+
+	_, err := BatchUpdateByID[testUser](db, []BatchUpdateItem{
+		{ID: 1, Updates: map[string]interface{}{"age": 21, "version": 2}},
+	})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("want ErrVersionConflict, got %v", err)
+	}
+
+	sql := rec.last("UPDATE")
+	if !strings.Contains(sql, "WHERE `version` = ? AND `id` = ?") {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+	if rec.last("ROLLBACK") == "" {
+		t.Fatalf("batch was not rolled back: %v", rec.SQL())
+	}
+}
+
+func TestBatchUpdateByFiltersVersionConflict(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.rowsAffected, rec.result = 0, existingUser
+
+	age := 20
+	_, err := BatchUpdateByFilters[testUser](db, []BatchUpdateByFilterItem{
+		{Filters: struct {
+			Age *int `json:"age" filter:"gte"`
+		}{Age: &age}, Updates: map[string]interface{}{"name": "bob", "version": 2}},
+	})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("want ErrVersionConflict, got %v", err)
+	}
+	if rec.last("ROLLBACK") == "" {
+		t.Fatalf("batch was not rolled back: %v", rec.SQL())
+	}
+
+	// 过滤条件没有命中任何记录时不算冲突
+	db, rec = recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.rowsAffected = 0
+	affected, err := BatchUpdateByFilters[testUser](db, []BatchUpdateByFilterItem{
+		{Filters: struct {
+			Age *int `json:"age" filter:"gte"`
+		}{Age: &age}, Updates: map[string]interface{}{"name": "bob", "version": 2}},
+	})
+	if err != nil || affected != 0 {
+		t.Fatalf("BatchUpdateByFilters = %d, %v", affected, err)
+	}
+}
+
+func TestDeleteByIDVersion(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.rowsAffected, rec.result = 0, existingUser
+
+	if err := deleteByID[testUser](db, 1, int64(2)); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("want ErrVersionConflict, got %v", err)
+	}
+	if sql := rec.last("DELETE"); !strings.Contains(sql, "`id` = ? AND `version` = ? | 1, 2") {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+
+	// 不带版本号时没有命中记录即为不存在
+	if err := DeleteByID[testUser](db, 1); err == nil || errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("want not found, got %v", err)
+	}
+}
+
+func TestBatchDeleteUsesPrimaryKey(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+
+	if _, err := BatchDelete[testUser](db, []interface{}{1, 2}); err != nil {
+		t.Fatalf("BatchDelete: %v", err)
+	}
+	if sql := rec.last("DELETE"); !strings.Contains(sql, "WHERE `id` IN (?,?)") {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+}
@@ -0,0 +1,95 @@
+package dbkit
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidColumn = errors.New("invalid column")
+
+// ColumnError 客户端传入的列名无法在模型 schema 中解析
+type ColumnError struct {
+	Column string
+}
+
+func (e *ColumnError) Error() string {
+	return fmt.Sprintf("invalid column %q", e.Column)
+}
+
+func (e *ColumnError) Unwrap() error {
+	return ErrInvalidColumn
+}
+
+// identRegexp 未指定 Model 时允许的标识符格式
+var identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// resolveColumn 将 json 名/列名/字段名解析为数据库列名。
+// 指定了 Model 时必须是 schema 中的字段，否则只接受普通标识符；策略隐藏的字段返回 403。
+func (qb *QueryBuilder) resolveColumn(name string) (string, error) {
+	column, field, err := qb.lookupColumn(name, lookupField)
+	if err != nil {
+		return "", err
+	}
+	if field != nil {
+		if err := qb.checkHidden(field); err != nil {
+			return "", err
+		}
+	}
+	return column, nil
+}
+
+// lookupColumn 解析列名，指定了 Model 时用 lookup 查找并同时返回对应的字段
+func (qb *QueryBuilder) lookupColumn(name string, lookup func(*schema.Schema, string) *schema.Field) (string, *schema.Field, error) {
+	if sch := qb.modelSchema(); sch != nil {
+		if field := lookup(sch, name); field != nil {
+			return field.DBName, field, nil
+		}
+		return "", nil, &ColumnError{Column: name}
+	}
+
+	if !identRegexp.MatchString(name) {
+		return "", nil, &ColumnError{Column: name}
+	}
+	return name, nil, nil
+}
+
+// quote 按数据库方言为标识符加引号
+func (qb *QueryBuilder) quote(name string) string {
+	return qb.db.Statement.Quote(name)
+}
+
+// quoteColumn 解析并加引号，用于拼接 SQL
+func (qb *QueryBuilder) quoteColumn(name string) (string, error) {
+	column, err := qb.resolveColumn(name)
+	if err != nil {
+		return "", err
+	}
+	return qb.quote(column), nil
+}
+
+// resolveUpdates 将更新字段解析为数据库列名，未知字段返回 ColumnError。
+// 指定了 Model 时拒绝更新主键、update:"-" 字段、软删除字段、租户字段与 GORM 只读字段（如 gorm:"<-:create"）。
+// 策略隐藏与 json:"-" 的字段只是不可读，仍可写入（如密码）。
+func (qb *QueryBuilder) resolveUpdates(updates map[string]interface{}) (map[string]interface{}, error) {
+	columns := make(map[string]interface{}, len(updates))
+	for name, value := range updates {
+		column, field, err := qb.lookupColumn(name, schemaField)
+		if err != nil {
+			return nil, err
+		}
+		if field != nil && readonlyField(field) {
+			return nil, fmt.Errorf("%w: column %s cannot be updated", ErrInvalidColumn, column)
+		}
+		columns[column] = value
+	}
+	return columns, nil
+}
+
+// readonlyField 字段是否禁止更新
+func readonlyField(field *schema.Field) bool {
+	return field.PrimaryKey || !field.Updatable || field.Tag.Get("update") == "-" || field.FieldType == deletedAtType ||
+		isTenantField(field)
+}
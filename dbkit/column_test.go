@@ -0,0 +1,81 @@
+package dbkit
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// secretUser Password 在 json 中隐藏
+type secretUser struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Name     string `json:"name"`
+	Password string `json:"-"`
+}
+
+func (secretUser) TableName() string { return "user" }
+
+func TestHiddenJSONColumnsAreRejected(t *testing.T) {
+	db := dryRunDB(t)
+	password := "desc"
+
+	tests := []struct {
+		name  string
+		apply func(qb *QueryBuilder) error
+	}{
+		{name: "group by", apply: func(qb *QueryBuilder) error {
+			_, err := qb.applyGroupSpec(GroupSpec{GroupBy: []string{"password"}})
+			return err
+		}},
+		{name: "group by field name", apply: func(qb *QueryBuilder) error {
+			_, err := qb.applyGroupSpec(GroupSpec{GroupBy: []string{"Password"}})
+			return err
+		}},
+		{name: "aggregate", apply: func(qb *QueryBuilder) error {
+			_, err := qb.applyGroupSpec(GroupSpec{GroupBy: []string{"name"}, Aggregates: []Aggregate{{Func: "max", Column: "password"}}})
+			return err
+		}},
+		{name: "having", apply: func(qb *QueryBuilder) error {
+			_, err := qb.applyGroupSpec(GroupSpec{GroupBy: []string{"name"}, Having: []HavingCondition{
+				{Aggregate: Aggregate{Func: "max", Column: "password"}, Op: "gt", Value: "a"},
+			}})
+			return err
+		}},
+		{name: "order", apply: func(qb *QueryBuilder) error {
+			return qb.ApplyOrders(&struct {
+				Password *string `json:"password"`
+			}{Password: &password}).GetDB().Error
+		}},
+		{name: "dynamic filter", apply: func(qb *QueryBuilder) error {
+			return qb.ApplyDynamicFilter(DynamicFilter{{Field: "password", Op: "eq", Value: "x"}}).GetDB().Error
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			toSQL(t, db, func(tx *gorm.DB) *gorm.DB {
+				qb := NewQueryBuilder(tx.Model(&secretUser{}))
+				err = tt.apply(qb)
+				return qb.GetDB().Find(&[]map[string]interface{}{})
+			})
+			if !errors.Is(err, ErrInvalidColumn) && !errors.Is(err, ErrInvalidFilter) {
+				t.Fatalf("err = %v, want ErrInvalidColumn or ErrInvalidFilter", err)
+			}
+		})
+	}
+}
+
+func TestHiddenJSONColumnsRemainWritable(t *testing.T) {
+	db := dryRunDB(t)
+	qb := NewQueryBuilder(db.Model(&secretUser{}))
+
+	columns, err := qb.resolveUpdates(map[string]interface{}{"password": "secret"})
+	if err != nil {
+		t.Fatalf("resolveUpdates: %v", err)
+	}
+	if columns["password"] != "secret" {
+		t.Fatalf("columns = %v", columns)
+	}
+}
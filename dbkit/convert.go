@@ -0,0 +1,124 @@
+package dbkit
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// timeLayouts 数据库以文本返回时间时可能的格式
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// toFloat64 将驱动返回的数值（包括 MySQL DECIMAL 返回的 []byte）转换为 float64
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case nil:
+		return 0, false
+	case []byte:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// toInt64 将驱动返回的整数转换为 int64
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case []byte:
+		if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+			return i, true
+		}
+	case string:
+		if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return i, true
+		}
+	}
+
+	// 如 FLOOR 在 MySQL 中返回 "3.0" 形式的文本
+	f, ok := toFloat64(v)
+	return int64(f), ok
+}
+
+// convertTo 按目标类型（通常是 schema 字段类型）转换驱动返回的值，无法转换时原样返回
+func convertTo(v interface{}, t reflect.Type) interface{} {
+	if v == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		switch s := v.(type) {
+		case []byte:
+			return parseTime(string(s), string(s))
+		case string:
+			return parseTime(s, v)
+		}
+		return v
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := toInt64(v); ok {
+			return reflect.ValueOf(i).Convert(t).Interface()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, ok := toInt64(v); ok && i >= 0 {
+			return reflect.ValueOf(uint64(i)).Convert(t).Interface()
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := toFloat64(v); ok {
+			return reflect.ValueOf(f).Convert(t).Interface()
+		}
+	case reflect.Bool:
+		switch b := v.(type) {
+		case bool:
+			return b
+		case []byte:
+			if parsed, err := strconv.ParseBool(string(b)); err == nil {
+				return parsed
+			}
+		default:
+			if i, ok := toInt64(v); ok {
+				return i != 0
+			}
+		}
+	case reflect.String:
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+	}
+
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+func parseTime(s string, fallback interface{}) interface{} {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t
+		}
+	}
+	return fallback
+}
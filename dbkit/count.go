@@ -0,0 +1,86 @@
+package dbkit
+
+import (
+	"gorm.io/gorm"
+)
+
+// TotalMode 总数统计方式
+type TotalMode string
+
+const (
+	TotalExact    TotalMode = "exact"    // COUNT(*) 精确统计
+	TotalNone     TotalMode = "none"     // 不统计，多取一行判断 has_more
+	TotalEstimate TotalMode = "estimate" // 使用数据库表统计信息估算，带过滤条件、租户或策略限制时退化为精确统计
+)
+
+// resolveTotalMode 结合请求中的 with_total 开关确定统计方式，游标分页只在 with_total=true 时统计
+func resolveTotalMode(page *Page, mode TotalMode) TotalMode {
+	if page == nil || page.WithTotal == nil {
+		if page.IsCursor() {
+			return TotalNone
+		}
+		return mode
+	}
+	if !*page.WithTotal {
+		return TotalNone
+	}
+	if mode == TotalNone {
+		return TotalExact
+	}
+	return mode
+}
+
+// countTotal 按统计方式计算总数，返回实际使用的统计方式
+func countTotal(qb *QueryBuilder, mode TotalMode) (int64, TotalMode, error) {
+	var total int64
+
+	switch mode {
+	case TotalNone:
+		return 0, TotalNone, nil
+	case TotalEstimate:
+		if !hasConditions(qb.db) && !scoped(qb.db) {
+			if n, ok := estimateCount(qb.db); ok {
+				return n, TotalEstimate, nil
+			}
+		}
+	}
+
+	if err := qb.db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return 0, TotalExact, err
+	}
+	return total, TotalExact, nil
+}
+
+// scoped 语句是否限定了租户或带有策略的强制过滤条件，表统计信息中的行数包含其他租户与范围外的记录
+func scoped(db *gorm.DB) bool {
+	if _, ok := db.Get(tenantSettingKey); ok {
+		return true
+	}
+	_, ok := db.Get(policyScopeSettingKey)
+	return ok
+}
+
+// estimateCount 读取表统计信息中的行数，不支持的数据库返回 false
+func estimateCount(db *gorm.DB) (int64, bool) {
+	sch, err := parseSchema(db, db.Statement.Model)
+	if err != nil {
+		return 0, false
+	}
+
+	var sql string
+	switch db.Dialector.Name() {
+	case "mysql":
+		sql = "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+	case "postgres":
+		sql = "SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(?)"
+	default:
+		return 0, false
+	}
+
+	var n *int64
+	tx := db.Session(&gorm.Session{NewDB: true}).Raw(sql, sch.Table).Scan(&n)
+	if tx.Error != nil || tx.RowsAffected == 0 || n == nil || *n < 0 {
+		return 0, false
+	}
+	return *n, true
+}
@@ -0,0 +1,251 @@
+package dbkit
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor 游标分页令牌，记录翻页边界行的排序键（排序字段 + 主键兜底）
+type Cursor struct {
+	Prev   bool              `json:"p,omitempty"` // true 表示向前翻页
+	Keys   []string          `json:"k"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// Encode 编码为不透明的令牌字符串
+func (c *Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor 解析令牌字符串
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || len(c.Keys) != len(c.Values) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// cursorOrders 游标分页使用的排序字段：请求中的排序 + 主键兜底，保证顺序唯一。
+// 可为 NULL 的列不能作为游标排序字段：NULL 与边界值比较永远不成立，翻页时会漏掉这些行
+func cursorOrders(sch *schema.Schema, orders []orderField) ([]orderField, error) {
+	pk := sch.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, fmt.Errorf("cursor pagination requires a primary key on %s", sch.Name)
+	}
+
+	hasPK := false
+	for _, o := range orders {
+		if o.Column == pk.DBName {
+			hasPK = true
+			continue
+		}
+		if field := sch.LookUpField(o.Column); field != nil && nullableField(field) {
+			return nil, fmt.Errorf("%w: cursor pagination cannot order by nullable column %s", ErrInvalidColumn, o.Column)
+		}
+	}
+	if hasPK {
+		return orders, nil
+	}
+
+	desc := false
+	if len(orders) > 0 {
+		desc = orders[len(orders)-1].Desc
+	}
+	return append(orders, orderField{Column: pk.DBName, Desc: desc}), nil
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// nullableField 列是否可能为 NULL：未声明 NOT NULL，且 Go 类型为指针或 sql.Null* 等 Scanner（如 gorm.DeletedAt）
+func nullableField(field *schema.Field) bool {
+	if field.NotNull || field.PrimaryKey {
+		return false
+	}
+	return field.FieldType.Kind() == reflect.Ptr || reflect.PointerTo(field.FieldType).Implements(scannerType)
+}
+
+// applyCursor 按游标追加 keyset 条件与排序，向前翻页时排序方向取反
+func (qb *QueryBuilder) applyCursor(sch *schema.Schema, orders []orderField, cursor *Cursor) error {
+	prev := cursor != nil && cursor.Prev
+
+	if cursor != nil && len(cursor.Keys) > 0 {
+		if len(cursor.Keys) != len(orders) {
+			return ErrInvalidCursor
+		}
+
+		values := make([]interface{}, len(orders))
+		for i, o := range orders {
+			if cursor.Keys[i] != o.Column {
+				return ErrInvalidCursor
+			}
+
+			field := sch.LookUpField(o.Column)
+			if field == nil {
+				return ErrInvalidCursor
+			}
+
+			v := reflect.New(field.FieldType)
+			if err := json.Unmarshal(cursor.Values[i], v.Interface()); err != nil {
+				return ErrInvalidCursor
+			}
+			values[i] = v.Elem().Interface()
+		}
+
+		sql, vars := qb.keysetCondition(orders, values, prev)
+		qb.db = qb.db.Where(sql, vars...)
+	}
+
+	for _, o := range orders {
+		if prev {
+			o.Desc = !o.Desc
+		}
+		qb.applyOrder(o)
+	}
+
+	return nil
+}
+
+// keysetCondition 生成 (a > ?) OR (a = ? AND b > ?) ... 形式的条件
+func (qb *QueryBuilder) keysetCondition(orders []orderField, values []interface{}, prev bool) (string, []interface{}) {
+	var groups []string
+	var vars []interface{}
+
+	for i, o := range orders {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = ?", qb.quote(orders[j].Column)))
+			vars = append(vars, values[j])
+		}
+
+		op := ">"
+		if o.Desc != prev {
+			op = "<"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s ?", qb.quote(o.Column), op))
+		vars = append(vars, values[i])
+
+		groups = append(groups, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(groups, " OR ") + ")", vars
+}
+
+// queryCursor 执行游标分页查询并生成前后页令牌
+func queryCursor[T any, R any](qb *QueryBuilder, page *Page, orders interface{}, result *PageResult[R]) error {
+	var model T
+	sch, err := parseSchema(qb.db, &model)
+	if err != nil {
+		return err
+	}
+
+	var cursor *Cursor
+	if *page.Cursor != "" {
+		if cursor, err = DecodeCursor(*page.Cursor); err != nil {
+			return err
+		}
+	}
+
+	fields, err := cursorOrders(sch, qb.parseOrders(orders))
+	if err != nil {
+		return err
+	}
+
+	if err := qb.applyCursor(sch, fields, cursor); err != nil {
+		return err
+	}
+
+	var rows []R
+	if err := qb.db.Limit(page.PageSize + 1).Find(&rows).Error; err != nil {
+		return err
+	}
+
+	hasMore := len(rows) > page.PageSize
+	if hasMore {
+		rows = rows[:page.PageSize]
+	}
+
+	prev := cursor != nil && cursor.Prev
+	if prev {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	result.Data = rows
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var elem R
+	rowSchema, err := parseSchema(qb.db, &elem)
+	if err != nil {
+		return err
+	}
+
+	first, err := encodeCursor(qb.db, rowSchema, fields, rows[0], true)
+	if err != nil {
+		return err
+	}
+	last, err := encodeCursor(qb.db, rowSchema, fields, rows[len(rows)-1], false)
+	if err != nil {
+		return err
+	}
+
+	if prev {
+		result.NextCursor = last
+		if hasMore {
+			result.PrevCursor = first
+		}
+	} else {
+		if hasMore {
+			result.NextCursor = last
+		}
+		if cursor != nil && len(cursor.Keys) > 0 {
+			result.PrevCursor = first
+		}
+	}
+	result.HasMore = result.NextCursor != ""
+
+	return nil
+}
+
+// encodeCursor 从结果行中取出排序键生成令牌
+func encodeCursor(db *gorm.DB, sch *schema.Schema, orders []orderField, row interface{}, prev bool) (string, error) {
+	rv := reflect.ValueOf(row)
+	cursor := &Cursor{Prev: prev}
+
+	for _, o := range orders {
+		field := sch.LookUpField(o.Column)
+		if field == nil {
+			return "", fmt.Errorf("cursor pagination requires column %s in result", o.Column)
+		}
+
+		value, _ := field.ValueOf(db.Statement.Context, rv)
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+
+		cursor.Keys = append(cursor.Keys, o.Column)
+		cursor.Values = append(cursor.Values, raw)
+	}
+
+	return cursor.Encode(), nil
+}
@@ -0,0 +1,154 @@
+package dbkit
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type cursorTestOrders struct {
+	Age *string `json:"age"`
+}
+
+type cursorRequest = BaseQueryRequest[struct{}, cursorTestOrders]
+
+func TestCursorEncodeDecode(t *testing.T) {
+	c := &Cursor{Prev: true, Keys: []string{"age", "id"}, Values: []json.RawMessage{json.RawMessage("30"), json.RawMessage("7")}}
+
+	got, err := DecodeCursor(c.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("round trip = %+v, want %+v", got, c)
+	}
+
+	invalid := map[string]string{
+		"not base64":      "!!!",
+		"not json":        base64.RawURLEncoding.EncodeToString([]byte("{")),
+		"length mismatch": base64.RawURLEncoding.EncodeToString([]byte(`{"k":["age","id"],"v":[30]}`)),
+	}
+	for name, token := range invalid {
+		if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: err = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	desc := "desc"
+	page := func(cursor string) *cursorRequest {
+		return &cursorRequest{Page: &Page{PageSize: 2, Cursor: &cursor}, Orders: cursorTestOrders{Age: &desc}}
+	}
+
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.result = func(string) ([]string, [][]driver.Value) {
+		return []string{"id", "name", "age"}, [][]driver.Value{
+			{int64(9), "a", int64(40)},
+			{int64(7), "b", int64(30)},
+			{int64(5), "c", int64(30)},
+		}
+	}
+
+	first, err := QueryPage[testUser](db, page(""))
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if sql := rec.last("SELECT"); !strings.Contains(sql, "ORDER BY `age` DESC,`id` DESC LIMIT 3") {
+		t.Fatalf("unexpected first page sql: %s", sql)
+	}
+	if rec.last("count(*)") != "" {
+		t.Fatal("cursor pages must not count by default")
+	}
+	if len(first.Data) != 2 || !first.HasMore || first.PrevCursor != "" {
+		t.Fatalf("first page = %d rows, has_more %v, prev %q", len(first.Data), first.HasMore, first.PrevCursor)
+	}
+
+	next, err := DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("next cursor: %v", err)
+	}
+	want := &Cursor{Keys: []string{"age", "id"}, Values: []json.RawMessage{json.RawMessage("30"), json.RawMessage("7")}}
+	if !reflect.DeepEqual(next, want) {
+		t.Fatalf("next cursor = %+v, want %+v", next, want)
+	}
+
+	if _, err := QueryPage[testUser](db, page(first.NextCursor)); err != nil {
+		t.Fatalf("next page: %v", err)
+	}
+	wantSQL := "WHERE ((`age` < ?) OR (`age` = ? AND `id` < ?)) " +
+		"ORDER BY `age` DESC,`id` DESC LIMIT 3 | 30, 30, 7"
+	if sql := rec.last("SELECT"); !strings.HasSuffix(sql, wantSQL) {
+		t.Fatalf("unexpected next page sql:\n got: %s\nwant suffix: %s", sql, wantSQL)
+	}
+
+	// 向前翻页时比较与排序方向都取反
+	prev := (&Cursor{Prev: true, Keys: want.Keys, Values: want.Values}).Encode()
+	if _, err := QueryPage[testUser](db, page(prev)); err != nil {
+		t.Fatalf("prev page: %v", err)
+	}
+	wantSQL = "WHERE ((`age` > ?) OR (`age` = ? AND `id` > ?)) " +
+		"ORDER BY `age` ASC,`id` ASC LIMIT 3 | 30, 30, 7"
+	if sql := rec.last("SELECT"); !strings.HasSuffix(sql, wantSQL) {
+		t.Fatalf("unexpected prev page sql:\n got: %s\nwant suffix: %s", sql, wantSQL)
+	}
+}
+
+func TestCursorMustMatchOrders(t *testing.T) {
+	db, _ := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+
+	// 令牌来自按 name 排序的查询，换成按 age 排序时拒绝
+	token := (&Cursor{Keys: []string{"name", "id"}, Values: []json.RawMessage{json.RawMessage(`"b"`), json.RawMessage("7")}}).Encode()
+	desc := "desc"
+	req := &cursorRequest{Page: &Page{PageSize: 2, Cursor: &token}, Orders: cursorTestOrders{Age: &desc}}
+	if _, err := QueryPage[testUser](db, req); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+// nullableUser Score 可为 NULL
+type nullableUser struct {
+	ID    uint `gorm:"primaryKey" json:"id"`
+	Score *int `json:"score"`
+	Rank  *int `json:"rank" gorm:"not null"`
+}
+
+func (nullableUser) TableName() string { return "user" }
+
+func TestCursorRejectsNullableOrders(t *testing.T) {
+	db, _ := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	cursor, asc := "", "asc"
+
+	req := &BaseQueryRequest[struct{}, struct {
+		Score *string `json:"score"`
+	}]{Page: &Page{PageSize: 2, Cursor: &cursor}}
+	req.Orders.Score = &asc
+	if _, err := QueryPage[nullableUser](db, req); !errors.Is(err, ErrInvalidColumn) {
+		t.Fatalf("nullable order: err = %v, want ErrInvalidColumn", err)
+	}
+
+	notNull := &BaseQueryRequest[struct{}, struct {
+		Rank *string `json:"rank"`
+	}]{Page: &Page{PageSize: 2, Cursor: &cursor}}
+	notNull.Orders.Rank = &asc
+	if _, err := QueryPage[nullableUser](db, notNull); err != nil {
+		t.Fatalf("not null order: %v", err)
+	}
+}
+
+func TestCursorRequiresPageSize(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	cursor := ""
+
+	_, err := QueryPage[testUser](db, &cursorRequest{Page: &Page{Cursor: &cursor}})
+	if !errors.Is(err, ErrInvalidPage) {
+		t.Fatalf("err = %v, want ErrInvalidPage", err)
+	}
+	if len(rec.SQL()) != 0 {
+		t.Fatalf("no query should run: %v", rec.SQL())
+	}
+}
@@ -0,0 +1,120 @@
+package dbkit
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// DynamicCondition 运行时由客户端指定字段与操作符的过滤条件
+type DynamicCondition struct {
+	Field string      `json:"field"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// DynamicFilter 动态过滤条件列表，如 [{"field":"age","op":"gte","value":18}]。
+// 字段必须是实体上的数据库字段，操作符受实体字段 ops 标签约束：
+//
+//	Age int `json:"age" ops:"eq,gte,lt"` // 只允许这三种操作符
+//	Pwd string `json:"-" ops:"-"`          // 禁止动态过滤
+//
+// 未设置 ops 标签时按字段类型使用默认的操作符集合。
+type DynamicFilter []DynamicCondition
+
+var (
+	stringOps  = []string{"eq", "ne", "in", "not_in", "like", "starts_with", "ends_with", "ilike", "is_null", "is_not_null"}
+	compareOps = []string{"eq", "ne", "gt", "gte", "lt", "lte", "in", "not_in", "between", "is_null", "is_not_null"}
+	boolOps    = []string{"eq", "ne", "is_null", "is_not_null"}
+)
+
+// ApplyDynamicFilter 校验并应用动态过滤条件，需要先通过 Model 指定实体
+func (qb *QueryBuilder) ApplyDynamicFilter(filter DynamicFilter) *QueryBuilder {
+	if len(filter) == 0 {
+		return qb
+	}
+
+	sch := qb.modelSchema()
+	if sch == nil {
+		qb.db.AddError(fmt.Errorf("%w: dynamic filter requires a model", ErrInvalidFilter))
+		return qb
+	}
+
+	for _, cond := range filter {
+		field := lookupField(sch, cond.Field)
+		if field == nil {
+			qb.db.AddError(fmt.Errorf("%w: unknown field %q", ErrInvalidFilter, cond.Field))
+			continue
+		}
+
+		if err := qb.checkHidden(field); err != nil {
+			qb.db.AddError(err)
+			continue
+		}
+
+		if !allowedOp(field, cond.Op) {
+			qb.db.AddError(fmt.Errorf("%w: operator %q is not allowed on %q", ErrInvalidFilter, cond.Op, cond.Field))
+			continue
+		}
+
+		qb.applyFilter(qb.quote(field.DBName), cond.Op, coerceValue(cond.Value, field.FieldType))
+	}
+
+	return qb
+}
+
+// coerceValue 将文本形式的值（如查询串参数）转换为字段类型，其他值原样返回
+func coerceValue(value interface{}, t reflect.Type) interface{} {
+	switch v := value.(type) {
+	case string:
+		return convertTo(v, t)
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = convertTo(item, t)
+		}
+		return items
+	}
+	return value
+}
+
+// allowedOp 判断字段是否允许使用该操作符
+func allowedOp(field *schema.Field, op string) bool {
+	for _, allowed := range fieldOps(field) {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldOps 字段允许的动态操作符：ops 标签优先，否则按类型推断
+func fieldOps(field *schema.Field) []string {
+	if tag, ok := field.Tag.Lookup("ops"); ok {
+		if tag == "-" || tag == "" {
+			return nil
+		}
+		return strings.Split(tag, ",")
+	}
+
+	// json 中隐藏的字段默认不允许过滤
+	if jsonName(field) == "-" {
+		return nil
+	}
+
+	// 使用 GORMDataType，type 标签（如 varchar(32)）不影响类型推断
+	switch field.GORMDataType {
+	case schema.String:
+		return stringOps
+	case schema.Int, schema.Uint, schema.Float, schema.Time:
+		return compareOps
+	case schema.Bool:
+		return boolOps
+	}
+	return []string{"eq", "ne", "is_null", "is_not_null"}
+}
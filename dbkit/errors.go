@@ -0,0 +1,169 @@
+package dbkit
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// 错误码，响应体中的 error_code，供客户端按类型处理错误
+const (
+	CodeBadRequest         = "bad_request"
+	CodeValidationFailed   = "validation_failed"
+	CodeFilterRequired     = "filter_required"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodePreconditionFailed = "precondition_failed"
+	CodeVersionRequired    = "version_required"
+	CodeInternal           = "internal_error"
+)
+
+// MySQL 错误号
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlRowIsReferenced = 1451
+	mysqlNoReferencedRow = 1452
+	mysqlLockWaitTimeout = 1205
+	mysqlDeadlock        = 1213
+)
+
+// APIError 带 HTTP 状态码与错误码的错误，通用处理器按其响应，Err 为原始错误
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Errors  []FieldError // 校验失败时的字段错误
+	Err     error
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// NewAPIError 创建 APIError，如 dbkit.NewAPIError(http.StatusConflict, dbkit.CodeConflict, "name already taken")
+func NewAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// Abort 创建中止请求的错误，错误码按状态码推断，如 dbkit.Abort(http.StatusForbidden, "no permission")
+func Abort(status int, message string) error {
+	return NewAPIError(status, statusCode(status), message)
+}
+
+// statusCode 状态码对应的默认错误码
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusPreconditionRequired:
+		return CodeVersionRequired
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// TranslateError 将错误转换为 APIError：
+//
+//	记录不存在                    -> 404 not_found
+//	唯一键冲突、外键约束、死锁     -> 409 conflict
+//	乐观锁版本冲突                -> 409 conflict
+//	If-Match 不匹配               -> 412 precondition_failed
+//	更新带版本字段的模型未带版本号 -> 428 version_required
+//	校验失败                      -> 400 validation_failed
+//	未指定过滤条件                -> 400 filter_required
+//	非法的列、过滤条件、游标等     -> 400 bad_request
+//	其他错误                      -> 500 internal_error（不向客户端暴露原始信息）
+func TranslateError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return &APIError{
+			Status:  http.StatusBadRequest,
+			Code:    CodeValidationFailed,
+			Message: ErrValidation.Error(),
+			Errors:  validationErr.Errors,
+			Err:     err,
+		}
+	}
+
+	if status, code, message, ok := translateDBError(err); ok {
+		return &APIError{Status: status, Code: code, Message: message, Err: err}
+	}
+
+	switch {
+	case errors.Is(err, ErrPreconditionFailed):
+		return &APIError{Status: http.StatusPreconditionFailed, Code: CodePreconditionFailed, Message: err.Error(), Err: err}
+	case errors.Is(err, ErrVersionRequired):
+		return &APIError{Status: http.StatusPreconditionRequired, Code: CodeVersionRequired, Message: err.Error(), Err: err}
+	case errors.Is(err, ErrVersionConflict):
+		return &APIError{Status: http.StatusConflict, Code: CodeConflict, Message: err.Error(), Err: err}
+	case errors.Is(err, ErrFilterRequired):
+		return &APIError{Status: http.StatusBadRequest, Code: CodeFilterRequired, Message: err.Error(), Err: err}
+	case errors.Is(err, ErrInvalidCursor), errors.Is(err, ErrInvalidPage), errors.Is(err, ErrInvalidFilter), errors.Is(err, ErrInvalidColumn),
+		errors.Is(err, ErrInvalidAggregate), errors.Is(err, ErrInvalidRequest), errors.Is(err, ErrSoftDeleteUnsupported),
+		errors.Is(err, ErrInvalidInclude):
+		return &APIError{Status: http.StatusBadRequest, Code: CodeBadRequest, Message: err.Error(), Err: err}
+	}
+
+	return &APIError{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// translateDBError 识别 GORM 与 MySQL 驱动返回的错误
+func translateDBError(err error) (status int, code, message string, ok bool) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, CodeNotFound, "record not found", true
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return http.StatusConflict, CodeConflict, "record already exists", true
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return http.StatusConflict, CodeConflict, "foreign key constraint violated", true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return 0, "", "", false
+	}
+	switch mysqlErr.Number {
+	case mysqlDuplicateEntry:
+		return http.StatusConflict, CodeConflict, "record already exists", true
+	case mysqlRowIsReferenced:
+		return http.StatusConflict, CodeConflict, "record is referenced by other records", true
+	case mysqlNoReferencedRow:
+		return http.StatusConflict, CodeConflict, "referenced record does not exist", true
+	case mysqlDeadlock, mysqlLockWaitTimeout:
+		return http.StatusConflict, CodeConflict, "concurrent update conflict, please retry", true
+	}
+	return 0, "", "", false
+}
+
+// RespondError 按 TranslateError 的结果以默认格式响应，响应体中的 code 与状态码一致，500 错误的原始信息记录到 c.Errors
+func RespondError(c *gin.Context, err error) {
+	writeError(c, EnvelopeWriter{}, err)
+}
@@ -0,0 +1,136 @@
+package dbkit
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// FieldsRequest 指定返回字段的查询请求
+type FieldsRequest interface {
+	GetFields() []string
+}
+
+// selectableField 解析客户端指定的返回字段，json:"-" 的字段不可选择
+func selectableField(sch *schema.Schema, name string) (*schema.Field, error) {
+	field := lookupField(sch, name)
+	if field == nil {
+		return nil, &ColumnError{Column: name}
+	}
+	return field, nil
+}
+
+// applyFields 只查询请求中指定的字段，另外总是查询主键、排序字段（游标需要）与加载关联所需的列。
+// dest 为结果类型（DTO）的 schema 时，字段名按 dest 解析，再映射到模型中同名的列；为 nil 时按模型解析
+func (qb *QueryBuilder) applyFields(req QueryRequest, dest *schema.Schema) *QueryBuilder {
+	r, ok := req.(FieldsRequest)
+	if !ok || len(r.GetFields()) == 0 {
+		return qb
+	}
+	sch := qb.modelSchema()
+	if sch == nil {
+		return qb
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	add := func(column string) {
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+
+	for _, name := range r.GetFields() {
+		var field *schema.Field
+		var err error
+		if dest != nil {
+			field, err = destField(sch, dest, name)
+		} else {
+			field, err = selectableField(sch, name)
+		}
+		if err == nil {
+			err = qb.checkHidden(field)
+		}
+		if err != nil {
+			_ = qb.db.AddError(err)
+			return qb
+		}
+		add(field.DBName)
+	}
+	if pk := sch.PrioritizedPrimaryField; pk != nil {
+		add(pk.DBName)
+	}
+	// 非法的排序字段由 ApplyOrders 报告，这里在独立会话中解析
+	orders := &QueryBuilder{db: qb.newSession(), sch: sch, hidden: qb.hidden}
+	for _, o := range orders.parseOrders(req.GetOrders()) {
+		add(o.Column)
+	}
+	if r, ok := req.(IncludeRequest); ok {
+		for _, inc := range r.GetIncludes() {
+			if rel := lookupRelation(sch, inc.Name); rel != nil {
+				for _, column := range parentColumns(rel) {
+					add(column)
+				}
+			}
+		}
+	}
+
+	return qb.ApplySelect(columns...)
+}
+
+// destField 将结果类型 dest 中的字段映射到模型 sch 中同名的列，没有对应列时返回 ColumnError
+func destField(sch, dest *schema.Schema, name string) (*schema.Field, error) {
+	field, err := selectableField(dest, name)
+	if err != nil {
+		return nil, err
+	}
+	if column := sch.LookUpField(field.DBName); column != nil && column.DBName != "" {
+		return column, nil
+	}
+	return nil, &ColumnError{Column: name}
+}
+
+// fieldNames 请求中返回字段在 T（实体或 DTO）中的 json 名（总是包括主键与加载的关联），未指定时返回 nil
+func fieldNames[T any](db *gorm.DB, req QueryRequest) (map[string]bool, error) {
+	r, ok := req.(FieldsRequest)
+	if !ok || len(r.GetFields()) == 0 {
+		return nil, nil
+	}
+	sch, err := parseSchema(db, new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(r.GetFields())+1)
+	if pk := sch.PrioritizedPrimaryField; pk != nil {
+		names[jsonName(pk)] = true
+	}
+	for _, name := range r.GetFields() {
+		field, err := selectableField(sch, name)
+		if err != nil {
+			return nil, err
+		}
+		names[jsonName(field)] = true
+	}
+	if r, ok := req.(IncludeRequest); ok {
+		for _, inc := range r.GetIncludes() {
+			if rel := lookupRelation(sch, inc.Name); rel != nil {
+				names[jsonName(rel.Field)] = true
+			}
+		}
+	}
+	return names, nil
+}
+
+// splitFields 拆分查询串中以逗号分隔的字段列表
+func splitFields(raw string) []string {
+	var fields []string
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
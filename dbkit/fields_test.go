@@ -0,0 +1,49 @@
+package dbkit
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// testUserItem 映射到 DTO 的查询结果，display_name 对应实体的 name 列
+type testUserItem struct {
+	ID          uint   `json:"id"`
+	DisplayName string `json:"display_name" gorm:"column:name"`
+	Age         int    `json:"age"`
+}
+
+func TestQueryToHandlerFields(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.result = existingUser
+	handler := GenericQueryToHandler[testUser, testUserItem, policyFilters, policyOrders](db)
+
+	w := serve("/query", http.MethodPost, "/query", `{"fields":["display_name"]}`, handler, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if sql := rec.last("SELECT `name`"); !strings.Contains(sql, "SELECT `name`,`id` FROM `user`") {
+		t.Fatalf("unexpected select: %v", rec.SQL())
+	}
+
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Data) != 1 {
+		t.Fatalf("unexpected response: %s", w.Body)
+	}
+	item := body.Data[0]
+	if item["display_name"] != "alice" || item["id"] == nil || item["age"] != nil {
+		t.Fatalf("fields are not masked by the DTO: %s", w.Body)
+	}
+
+	// 实体中有、DTO 中没有的字段
+	w = serve("/query", http.MethodPost, "/query", `{"fields":["tenant_id"]}`, handler, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400: %s", w.Code, w.Body)
+	}
+}
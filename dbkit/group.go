@@ -0,0 +1,182 @@
+package dbkit
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GroupOrder 分组结果排序，Field 为分组列或聚合别名
+type GroupOrder struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// GroupSpec 分组聚合配置，所有列名、函数、别名均经过校验
+type GroupSpec struct {
+	GroupBy    []string          `json:"group_by"`
+	Aggregates []Aggregate       `json:"aggregates"`
+	Having     []HavingCondition `json:"having"`
+	Orders     []GroupOrder      `json:"orders"`
+	Limit      int               `json:"limit"`
+}
+
+// GroupRequest 分组聚合请求
+type GroupRequest[F any] struct {
+	Filters F `json:"filters"`
+	GroupSpec
+}
+
+// groupColumn 分组结果中的一列及其类型
+type groupColumn struct {
+	alias string
+	typ   reflect.Type // nil 表示保持驱动返回值
+}
+
+var (
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+// GroupAggregate 按 spec 执行分组聚合，结果按 schema 中的列类型转换
+func GroupAggregate[T any](db *gorm.DB, filters interface{}, spec GroupSpec) ([]map[string]interface{}, error) {
+	if len(spec.GroupBy) == 0 {
+		return nil, fmt.Errorf("%w: group_by is required", ErrInvalidAggregate)
+	}
+
+	var model T
+	qb := NewQueryBuilder(db.Model(&model))
+	qb.ApplyFilters(filters)
+
+	columns, err := qb.applyGroupSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanGroupRows(qb.GetDB(), columns)
+}
+
+// applyGroupSpec 生成 SELECT / GROUP BY / HAVING / ORDER BY / LIMIT，返回结果列
+func (qb *QueryBuilder) applyGroupSpec(spec GroupSpec) ([]groupColumn, error) {
+	sch := qb.modelSchema()
+
+	var selects, groups []string
+	var columns []groupColumn
+	aliases := make(map[string]string) // 可排序的名称 -> SQL 中的引用
+
+	for _, name := range spec.GroupBy {
+		column, err := qb.resolveColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		quoted := qb.quote(column)
+		selects = append(selects, quoted)
+		groups = append(groups, quoted)
+
+		col := groupColumn{alias: column}
+		if sch != nil {
+			col.typ = sch.LookUpField(column).FieldType
+		}
+		columns = append(columns, col)
+		aliases[name], aliases[column] = quoted, quoted
+	}
+
+	for _, agg := range spec.Aggregates {
+		expr, err := qb.aggregateExpr(agg)
+		if err != nil {
+			return nil, err
+		}
+
+		alias := agg.Alias
+		if alias == "" {
+			alias = agg.Func
+			if agg.Column != "" && agg.Column != "*" {
+				alias += "_" + agg.Column
+			}
+		}
+		if !identRegexp.MatchString(alias) {
+			return nil, fmt.Errorf("%w: invalid alias %q", ErrInvalidAggregate, alias)
+		}
+		if _, exists := aliases[alias]; exists {
+			return nil, fmt.Errorf("%w: duplicate alias %q", ErrInvalidAggregate, alias)
+		}
+
+		selects = append(selects, expr+" AS "+qb.quote(alias))
+		columns = append(columns, groupColumn{alias: alias, typ: qb.aggregateType(agg)})
+		aliases[alias] = qb.quote(alias)
+	}
+
+	qb.db = qb.db.Select(selects).Group(strings.Join(groups, ", "))
+	qb.ApplyHavingConditions(spec.Having...)
+
+	for _, o := range spec.Orders {
+		ref, ok := aliases[o.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot order by %q", ErrInvalidAggregate, o.Field)
+		}
+		direction := "ASC"
+		if o.Desc {
+			direction = "DESC"
+		}
+		qb.db = qb.db.Order(ref + " " + direction)
+	}
+
+	if spec.Limit > 0 {
+		qb.db = qb.db.Limit(spec.Limit)
+	}
+
+	return columns, qb.db.Error
+}
+
+// aggregateType 聚合结果的类型：计数为 int64，求和/平均/方差为 float64，最值沿用列类型
+func (qb *QueryBuilder) aggregateType(agg Aggregate) reflect.Type {
+	switch agg.Func {
+	case "count", "count_distinct":
+		return int64Type
+	case "sum", "avg", "variance", "stddev":
+		return float64Type
+	}
+
+	if sch := qb.modelSchema(); sch != nil {
+		if field := lookupField(sch, agg.Column); field != nil {
+			return field.FieldType
+		}
+	}
+	return nil
+}
+
+// scanGroupRows 读取分组结果并按列类型转换
+func scanGroupRows(db *gorm.DB, columns []groupColumn) ([]map[string]interface{}, error) {
+	rows, err := db.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			if col.typ == nil {
+				row[col.alias] = convertTo(values[i], reflect.TypeOf(""))
+				continue
+			}
+			row[col.alias] = convertTo(values[i], col.typ)
+		}
+		results = append(results, row)
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,46 @@
+package dbkit
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hideID 查询时隐藏 id 的策略
+type hideID struct{ AllowAll[secretUser] }
+
+func (hideID) CanQuery(c *gin.Context) (*Permission, error) {
+	return &Permission{Hidden: []string{"id"}}, nil
+}
+
+func TestGroupHandlerRejectsHiddenColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "group by", body: `{"group_by":["password"]}`, want: http.StatusBadRequest},
+		{name: "aggregate", body: `{"group_by":["name"],"aggregates":[{"func":"max","column":"password","alias":"m"}]}`, want: http.StatusBadRequest},
+		{name: "having", body: `{"group_by":["name"],"having":[{"func":"min","column":"password","op":"gt","value":"a"}]}`, want: http.StatusBadRequest},
+		{name: "policy hidden having", body: `{"group_by":["name"],"having":[{"func":"max","column":"id","op":"gt","value":1}]}`, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+			handler := GenericGroupHandler[secretUser, struct{}](db, WithPolicy[secretUser](hideID{}))
+
+			w := serve("/group", http.MethodPost, "/group", tt.body, handler, nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			for _, sql := range rec.SQL() {
+				if strings.Contains(sql, "password") {
+					t.Fatalf("hidden column was queried: %s", sql)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package dbkit
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -14,204 +16,971 @@ func generateUUID32() string {
 	return strings.ReplaceAll(uuid.New().String(), "-", "")
 }
 
+// scopeDB 绑定请求的 context（审计日志从中读取操作人与请求 ID），配置了 WithTenant 时限定为当前租户
+func (o *options) scopeDB(c *gin.Context, db *gorm.DB) (*gorm.DB, error) {
+	db = db.WithContext(c.Request.Context())
+	if o.tenant == nil {
+		return db, nil
+	}
+	tenant, err := o.tenant(c)
+	if err != nil {
+		return nil, err
+	}
+	return ScopeTenant(db, tenant), nil
+}
+
+// byID 按主键操作时传给钩子的查询请求
+func byID(id interface{}) *BaseQueryRequest[IDFilter, struct{}] {
+	return &BaseQueryRequest[IDFilter, struct{}]{Filters: IDFilter{IDs: []interface{}{id}}}
+}
+
 // GenericQueryHandler 通用查询处理器
-func GenericQueryHandler[T any, F any, O any](db *gorm.DB) gin.HandlerFunc {
+func GenericQueryHandler[T any, F any, O any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
 		var req BaseQueryRequest[F, O]
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		if err := BindJSON(c, &req); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		results, count, err := Query[T](db, &req)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+		if err := validateQuery(&req, o); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, SuccessWithPage(results, req.Page, count))
+		queryPageHandler[T](c, scoped, &req, hooks, policy, o, opts)
 	}
 }
 
-// GenericQueryToHandler 通用查询处理器（映射到DTO）
-func GenericQueryToHandler[T any, R any, F any, O any](db *gorm.DB) gin.HandlerFunc {
+// GenericListHandler 通用查询处理器（GET，过滤、排序、分页来自查询串，见 BindQueryString）
+func GenericListHandler[T any, F any, O any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
 		var req BaseQueryRequest[F, O]
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		if err := BindQueryString(c.Request.URL.Query(), &req); err != nil {
+			o.respondError(c, err)
+			return
+		}
+		if err := Validate(&req); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		results, count, err := QueryTo[T, R](db, &req)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+		if err := validateQuery(&req, o); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, SuccessWithPage(results, req.Page, count))
+		queryPageHandler[T](c, scoped, &req, hooks, policy, o, opts)
 	}
 }
 
-// GenericCreateHandler 通用创建处理器
-func GenericCreateHandler[T any](db *gorm.DB) gin.HandlerFunc {
+// queryPageHandler 执行分页查询并响应，查询前评估策略、执行钩子，指定了 fields 时只返回这些字段（及 include 的关联）
+func queryPageHandler[T any, F any, O any](c *gin.Context, db *gorm.DB, req *BaseQueryRequest[F, O], hooks hookChain[T], policy Policy[T], o *options, opts []Option) {
+	only, err := fieldNames[T](db, req)
+	if err != nil {
+		o.respondError(c, err)
+		return
+	}
+
+	perm, err := policy.CanQuery(c)
+	if err != nil {
+		o.respondError(c, err)
+		return
+	}
+	db = restrict[T](db, perm)
+
+	if err := hooks.beforeQuery(c, req); err != nil {
+		o.respondError(c, err)
+		return
+	}
+
+	result, err := QueryPage[T](db, req, opts...)
+	if err == nil {
+		err = hooks.afterQuery(c, result.Data)
+	}
+	if err != nil {
+		o.respondError(c, err)
+		return
+	}
+
+	writePage(c, o.writer, result, req.Page, fieldMask{only: only, hidden: hiddenNames[T](db, perm)})
+}
+
+// GenericQueryToHandler 通用查询处理器（映射到DTO）
+func GenericQueryToHandler[T any, R any, F any, O any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
-		var entity T
-		if err := c.ShouldBindJSON(&entity); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		var req BaseQueryRequest[F, O]
+		if err := BindJSON(c, &req); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		if err := Create(db, &entity); err != nil {
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+		if err := validateQuery(&req, o); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, Success(entity))
+		// 返回字段为 DTO 的字段
+		only, err := fieldNames[R](scoped, &req)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		perm, err := policy.CanQuery(c)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		if err := hooks.beforeQuery(c, &req); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		result, err := QueryPageTo[T, R](scoped, &req, opts...)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		writePage(c, o.writer, result, req.Page, fieldMask{only: only, hidden: hiddenNames[T](scoped, perm)})
 	}
 }
 
-// GenericCreateWithIDHandler 通用创建处理器（自动生成32位UUID）
-func GenericCreateWithIDHandler[T any](db *gorm.DB, idSetter func(*T, string)) gin.HandlerFunc {
+// GenericCreateHandler 通用创建处理器
+func GenericCreateHandler[T any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
 		var entity T
-		if err := c.ShouldBindJSON(&entity); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		if err := BindJSON(c, &entity); err != nil {
+			o.respondError(c, err)
+			return
+		}
+		if err := resetGuarded(scoped, &entity); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		// 生成32位UUID（去掉横杠）
-		id := generateUUID32()
-		idSetter(&entity, id)
+		perm, err := policy.CanCreate(c, &entity)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
 
-		if err := Create(db, &entity); err != nil {
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+		if err := hooks.beforeCreate(c, &entity); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, Success(entity))
+		err = createInScope(scoped, []*T{&entity}, []*Permission{perm}, func(tx *gorm.DB) error {
+			return Create(tx, &entity)
+		})
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		if err := hooks.afterCreate(c, &entity); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		o.writer.WriteResource(c, http.StatusOK, mask(entity, hiddenNames[T](scoped, perm)))
 	}
 }
 
-// GenericUpdateHandler 通用更新处理器
-func GenericUpdateHandler[T any, F any](db *gorm.DB) gin.HandlerFunc {
+// GenericCreateWithIDHandler 通用创建处理器（自动生成32位UUID），等同于 GenericCreateHandler 加 WithGeneratedID
+func GenericCreateWithIDHandler[T any](db *gorm.DB, idSetter func(*T, string), opts ...Option) gin.HandlerFunc {
+	return GenericCreateHandler[T](db, append([]Option{WithGeneratedID(idSetter)}, opts...)...)
+}
+
+// GenericUpdateHandler 通用更新处理器，U 为更新结构体（指针字段，非 nil 的字段参与更新，见 UpdatesOf）
+func GenericUpdateHandler[T any, F any, U any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	checkUpdatesType(reflect.TypeOf((*U)(nil)).Elem())
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
 		var req struct {
-			Filters F                      `json:"filters"`
-			Updates map[string]interface{} `json:"updates"`
+			Filters F `json:"filters"`
+			Updates U `json:"updates"`
 		}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		if err := BindJSON(c, &req); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		if len(req.Updates) == 0 {
-			c.JSON(http.StatusBadRequest, Error("No fields to update"))
+		updates, err := UpdatesOf(req.Updates)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		if len(updates) == 0 {
+			o.respondError(c, errNoUpdates)
 			return
 		}
 
-		affected, err := Update[T](db, req.Filters, req.Updates)
+		perm, err := policy.CanUpdate(c, req.Filters, updates)
+		if err == nil {
+			err = checkReadOnly[T](scoped, perm, updates)
+		}
 		if err != nil {
-			if err == ErrFilterRequired {
-				c.JSON(http.StatusBadRequest, Error(err.Error()))
-				return
-			}
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		if err := hooks.beforeUpdate(c, req.Filters, updates); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		affected, err := Update[T](scoped, req.Filters, updates)
+		if err == nil {
+			err = hooks.afterUpdate(c, req.Filters, affected)
+		}
+		if err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, Success(map[string]interface{}{
+		o.writer.WriteData(c, http.StatusOK, map[string]interface{}{
 			"affected": affected,
-		}))
+		})
 	}
 }
 
 // GenericDeleteHandler 通用删除处理器
-func GenericDeleteHandler[T any, F any](db *gorm.DB) gin.HandlerFunc {
+func GenericDeleteHandler[T any, F any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
 		var req struct {
 			Filters F `json:"filters"`
 		}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		if err := BindJSON(c, &req); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		affected, err := Delete[T](db, req.Filters)
+		perm, err := policy.CanDelete(c, req.Filters)
 		if err != nil {
-			if err == ErrFilterRequired {
-				c.JSON(http.StatusBadRequest, Error(err.Error()))
-				return
-			}
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		if err := hooks.beforeDelete(c, req.Filters); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, Success(map[string]interface{}{
+		affected, err := Delete[T](scoped, req.Filters)
+		if err == nil {
+			err = hooks.afterDelete(c, req.Filters, affected)
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		o.writer.WriteData(c, http.StatusOK, map[string]interface{}{
 			"affected": affected,
-		}))
+		})
 	}
 }
 
 // GenericGetOneHandler 通用获取单条记录处理器
-func GenericGetOneHandler[T any, F any, O any](db *gorm.DB) gin.HandlerFunc {
+func GenericGetOneHandler[T any, F any, O any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
 		var req BaseQueryRequest[F, O]
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		if err := BindJSON(c, &req); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		if err := validateQuery(&req, o); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		only, err := fieldNames[T](scoped, &req)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		perm, err := policy.CanQuery(c)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		if err := hooks.beforeQuery(c, &req); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		result, err := First[T](scoped, &req)
+		if err == nil {
+			rows := []T{*result}
+			err = hooks.afterQuery(c, rows)
+			result = &rows[0]
+		}
+		if err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		result, err := First[T](db, &req)
+		o.writer.WriteResource(c, http.StatusOK, fieldMask{only: only, hidden: hiddenNames[T](scoped, perm)}.apply(result))
+	}
+}
+
+// GenericGetByIDHandler 通用按主键获取处理器，主键取自路径参数 :id。
+// 响应带 ETag，If-None-Match 匹配时返回 304
+func GenericGetByIDHandler[T any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		perm, err := policy.CanQuery(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+			o.respondError(c, err)
 			return
 		}
+		scoped = restrict[T](scoped, perm)
 
-		c.JSON(http.StatusOK, Success(result))
+		id := c.Param("id")
+		if err := hooks.beforeQuery(c, byID(id)); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		result, err := GetByID[T](scoped, id)
+		if err == nil {
+			rows := []T{*result}
+			err = hooks.afterQuery(c, rows)
+			result = &rows[0]
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		etag := ETag(scoped, result)
+		if header := c.GetHeader("If-None-Match"); header != "" && etag != "" && etagMatches(header, etag) {
+			c.Header("ETag", etag)
+			c.Status(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+
+		setETag(c, scoped, result)
+		o.writer.WriteResource(c, http.StatusOK, mask(result, hiddenNames[T](scoped, perm)))
+	}
+}
+
+// GenericPatchByIDHandler 通用按主键部分更新处理器（PATCH /:id），请求体为更新结构体 U，返回更新后的记录。
+// 带 If-Match 时先校验 ETag，并以当前版本号作为更新条件
+func GenericPatchByIDHandler[T any, U any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	checkUpdatesType(reflect.TypeOf((*U)(nil)).Elem())
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		var body U
+		if err := BindJSON(c, &body); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		updates, err := UpdatesOf(body)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		if len(updates) == 0 {
+			o.respondError(c, errNoUpdates)
+			return
+		}
+
+		id := c.Param("id")
+		filters := IDFilter{IDs: []interface{}{id}}
+		perm, err := policy.CanUpdate(c, filters, updates)
+		if err == nil {
+			err = checkReadOnly[T](scoped, perm, updates)
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		version, err := checkIfMatch[T](c, scoped, id)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		if err := withVersion[T](scoped, updates, version); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		if err := hooks.beforeUpdate(c, filters, updates); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		result, err := UpdateByID[T](scoped, id, updates)
+		if err == nil {
+			err = hooks.afterUpdate(c, filters, 1)
+		}
+		if err != nil {
+			o.respondError(c, preconditionError(err, version != nil))
+			return
+		}
+
+		setETag(c, scoped, result)
+		o.writer.WriteResource(c, http.StatusOK, mask(result, hiddenNames[T](scoped, perm)))
+	}
+}
+
+// GenericReplaceByIDHandler 通用按主键整体替换处理器（PUT /:id），返回替换后的记录。
+// 带 If-Match 时先校验 ETag，并以当前版本号作为替换条件
+func GenericReplaceByIDHandler[T any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		var entity T
+		if err := BindJSON(c, &entity); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		id := c.Param("id")
+		perm, err := policy.CanUpdate(c, IDFilter{IDs: []interface{}{id}}, nil)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		version, err := checkIfMatch[T](c, scoped, id)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		if err := keepReadOnly(scoped, perm, id, &entity); err != nil {
+			o.respondError(c, err)
+			return
+		}
+		if err := setVersion(scoped, &entity, version); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		if err := hooks.beforeReplace(c, id, &entity); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		result, err := ReplaceByID[T](scoped, id, &entity)
+		if err == nil {
+			err = hooks.afterReplace(c, id, result)
+		}
+		if err != nil {
+			o.respondError(c, preconditionError(err, version != nil))
+			return
+		}
+
+		setETag(c, scoped, result)
+		o.writer.WriteResource(c, http.StatusOK, mask(result, hiddenNames[T](scoped, perm)))
+	}
+}
+
+// GenericDeleteByIDHandler 通用按主键删除处理器（DELETE /:id），带 If-Match 时先校验 ETag
+func GenericDeleteByIDHandler[T any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		id := c.Param("id")
+		filters := IDFilter{IDs: []interface{}{id}}
+		perm, err := policy.CanDelete(c, filters)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		version, err := checkIfMatch[T](c, scoped, id)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		if err := hooks.beforeDelete(c, filters); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		// 以 If-Match 校验得到的版本号作为删除条件，检查后被并发修改时返回 412
+		err = preconditionError(deleteByID[T](scoped, id, version), version != nil)
+		if err == nil {
+			err = hooks.afterDelete(c, filters, 1)
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		o.writer.WriteData(c, http.StatusOK, map[string]interface{}{
+			"affected": 1,
+		})
+	}
+}
+
+// GenericRestoreByIDHandler 恢复已软删除的记录（POST /:id/restore），返回恢复后的记录，需要 AllowDeleted 钩子放行
+func GenericRestoreByIDHandler[T any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		id := c.Param("id")
+		if err := hooks.allowDeleted(c); err != nil {
+			o.respondError(c, err)
+			return
+		}
+		perm, err := policy.CanDelete(c, IDFilter{IDs: []interface{}{id}})
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		if err := hooks.beforeRestore(c, id); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		result, err := RestoreByID[T](scoped, id)
+		if err == nil {
+			err = hooks.afterRestore(c, result)
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		setETag(c, scoped, result)
+		o.writer.WriteResource(c, http.StatusOK, mask(result, hiddenNames[T](scoped, perm)))
+	}
+}
+
+// GenericPurgeByIDHandler 永久删除记录（DELETE /:id/purge），包括已软删除的记录，需要 AllowDeleted 钩子放行
+func GenericPurgeByIDHandler[T any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		id := c.Param("id")
+		filters := IDFilter{IDs: []interface{}{id}}
+		if err := hooks.allowDeleted(c); err != nil {
+			o.respondError(c, err)
+			return
+		}
+		perm, err := policy.CanDelete(c, filters)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		if err := hooks.beforeDelete(c, filters); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		err = PurgeByID[T](scoped, id)
+		if err == nil {
+			err = hooks.afterDelete(c, filters, 1)
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		o.writer.WriteData(c, http.StatusOK, map[string]interface{}{
+			"affected": 1,
+		})
 	}
 }
 
 // GenericStatsHandler 通用统计处理器
-func GenericStatsHandler[T any, F any, O any](db *gorm.DB) gin.HandlerFunc {
+func GenericStatsHandler[T any, F any, O any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
 		var req struct {
 			BaseQueryRequest[F, O]
 			StatsConfig StatsConfig `json:"stats_config"`
 		}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		if err := BindJSON(c, &req); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		if err := validateQuery(&req.BaseQueryRequest, o); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		stats, err := Stats[T](db, &req.BaseQueryRequest, req.StatsConfig)
+		perm, err := policy.CanQuery(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+			o.respondError(c, err)
 			return
 		}
+		scoped = restrict[T](scoped, perm)
 
-		c.JSON(http.StatusOK, Success(stats))
+		if err := hooks.beforeQuery(c, &req.BaseQueryRequest); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		stats, err := Stats[T](scoped, &req.BaseQueryRequest, req.StatsConfig)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		o.writer.WriteData(c, http.StatusOK, stats)
 	}
 }
 
-// GenericBatchCreateHandler 通用批量创建处理器
-func GenericBatchCreateHandler[T any](db *gorm.DB, batchSize int) gin.HandlerFunc {
+// GenericGroupHandler 通用分组聚合处理器
+func GenericGroupHandler[T any, F any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
 	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		var req GroupRequest[F]
+		if err := BindJSON(c, &req); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		query := &BaseQueryRequest[F, struct{}]{Filters: req.Filters}
+		if err := validateQuery(query, o); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		perm, err := policy.CanQuery(c)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		if err := hooks.beforeQuery(c, query); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		results, err := GroupAggregate[T](scoped, req.Filters, req.GroupSpec)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		o.writer.WriteData(c, http.StatusOK, results)
+	}
+}
+
+// GenericBatchCreateHandler 通用批量创建处理器，BeforeCreate/AfterCreate 钩子逐条执行
+func GenericBatchCreateHandler[T any](db *gorm.DB, batchSize int, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
 		var entities []T
-		if err := c.ShouldBindJSON(&entities); err != nil {
-			c.JSON(http.StatusBadRequest, Error("Invalid request: "+err.Error()))
+		if err := BindJSON(c, &entities); err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		if err := BatchCreate(db, entities, batchSize); err != nil {
-			c.JSON(http.StatusInternalServerError, Error(err.Error()))
+		targets := make([]*T, len(entities))
+		perms := make([]*Permission, len(entities))
+		for i := range entities {
+			if err := resetGuarded(scoped, &entities[i]); err != nil {
+				o.respondError(c, err)
+				return
+			}
+			targets[i] = &entities[i]
+			if perms[i], err = policy.CanCreate(c, &entities[i]); err != nil {
+				o.respondError(c, err)
+				return
+			}
+			if err := hooks.beforeCreate(c, &entities[i]); err != nil {
+				o.respondError(c, err)
+				return
+			}
+		}
+
+		err = createInScope(scoped, targets, perms, func(tx *gorm.DB) error {
+			return BatchCreate(tx, entities, batchSize)
+		})
+		if err != nil {
+			o.respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, Success(map[string]interface{}{
+		for i := range entities {
+			if err := hooks.afterCreate(c, &entities[i]); err != nil {
+				o.respondError(c, err)
+				return
+			}
+		}
+
+		o.writer.WriteData(c, http.StatusOK, map[string]interface{}{
 			"created": len(entities),
-		}))
+		})
+	}
+}
+
+// GenericBatchUpdateHandler 通用批量更新处理器（不同ID不同值），U 与 GenericUpdateHandler 相同，BeforeUpdate 钩子逐条执行
+func GenericBatchUpdateHandler[T any, U any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	checkUpdatesType(reflect.TypeOf((*U)(nil)).Elem())
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		var reqItems []BatchUpdateRequestItem[U]
+		if err := BindJSON(c, &reqItems); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		items := make([]BatchUpdateItem, 0, len(reqItems))
+		ids := make([]interface{}, 0, len(reqItems))
+		perms := make([]*Permission, 0, len(reqItems))
+		for i, reqItem := range reqItems {
+			updates, err := UpdatesOf(reqItem.Updates)
+			if err != nil {
+				o.respondError(c, err)
+				return
+			}
+			if len(updates) == 0 {
+				o.respondError(c, &ValidationError{Errors: []FieldError{{
+					Field: fmt.Sprintf("[%d].updates", i), Rule: "required", Message: "no fields to update",
+				}}})
+				return
+			}
+
+			filters := IDFilter{IDs: []interface{}{reqItem.ID}}
+			perm, err := policy.CanUpdate(c, filters, updates)
+			if err == nil {
+				err = checkReadOnly[T](scoped, perm, updates)
+			}
+			if err != nil {
+				o.respondError(c, err)
+				return
+			}
+			if err := hooks.beforeUpdate(c, filters, updates); err != nil {
+				o.respondError(c, err)
+				return
+			}
+			items = append(items, BatchUpdateItem{ID: reqItem.ID, Updates: updates})
+			ids = append(ids, reqItem.ID)
+			perms = append(perms, perm)
+		}
+		scoped = restrict[T](scoped, perms...)
+
+		affected, err := BatchUpdateByID[T](scoped, items)
+		if err == nil {
+			err = hooks.afterUpdate(c, IDFilter{IDs: ids}, affected)
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		o.writer.WriteData(c, http.StatusOK, map[string]interface{}{
+			"affected": affected,
+		})
+	}
+}
+
+// GenericBatchDeleteHandler 通用批量删除处理器（根据ID列表）
+func GenericBatchDeleteHandler[T any](db *gorm.DB, opts ...Option) gin.HandlerFunc {
+	o := handlerOptions(db, opts)
+	hooks := hooksOf[T](o)
+	policy := policyOf[T](o)
+	return func(c *gin.Context) {
+		scoped, err := o.scopeDB(c, db)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		var req struct {
+			IDs []interface{} `json:"ids" binding:"required"`
+		}
+		if err := BindJSON(c, &req); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		filters := IDFilter{IDs: req.IDs}
+		perm, err := policy.CanDelete(c, filters)
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+		scoped = restrict[T](scoped, perm)
+
+		if err := hooks.beforeDelete(c, filters); err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		affected, err := BatchDelete[T](scoped, req.IDs)
+		if err == nil {
+			err = hooks.afterDelete(c, filters, affected)
+		}
+		if err != nil {
+			o.respondError(c, err)
+			return
+		}
+
+		o.writer.WriteData(c, http.StatusOK, map[string]interface{}{
+			"affected": affected,
+		})
 	}
 }
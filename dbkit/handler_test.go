@@ -0,0 +1,112 @@
+package dbkit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve 以 method、path 调用注册在 route 上的处理器，返回响应
+func serve(route, method, path, body string, handler gin.HandlerFunc, header http.Header) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Handle(method, route, handler)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDeleteByIDHandlerIfMatch(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.rowsAffected, rec.result = 0, existingUser
+	handler := GenericDeleteByIDHandler[testUser](db)
+
+	// 校验通过后记录被并发修改
+	w := serve("/:id", http.MethodDelete, "/1", "", handler, http.Header{"If-Match": {`"v3"`}})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status = %d, want 412: %s", w.Code, w.Body)
+	}
+	if sql := rec.last("DELETE"); !strings.Contains(sql, "`version` = ? | 1, 3") {
+		t.Fatalf("delete is not conditioned on the version: %s", sql)
+	}
+
+	w = serve("/:id", http.MethodDelete, "/1", "", handler, http.Header{"If-Match": {`"v2"`}})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status = %d, want 412: %s", w.Code, w.Body)
+	}
+}
+
+func TestPatchByIDHandlerRequiresVersion(t *testing.T) {
+	type updates struct {
+		Age     *int   `json:"age"`
+		Version *int64 `json:"version"`
+	}
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.result = existingUser
+	handler := GenericPatchByIDHandler[testUser, updates](db)
+
+	w := serve("/:id", http.MethodPatch, "/1", `{"age":21}`, handler, nil)
+	if w.Code != http.StatusPreconditionRequired || !strings.Contains(w.Body.String(), CodeVersionRequired) {
+		t.Fatalf("status = %d, want 428: %s", w.Code, w.Body)
+	}
+	if sql := rec.last("UPDATE"); sql != "" {
+		t.Fatalf("update without version was executed: %s", sql)
+	}
+
+	w = serve("/:id", http.MethodPatch, "/1", `{"age":21}`, handler, http.Header{"If-Match": {`"v3"`}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("If-Match: status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if sql := rec.last("UPDATE"); !strings.Contains(sql, "`version` = ?") {
+		t.Fatalf("update is not conditioned on the version: %s", sql)
+	}
+
+	w = serve("/:id", http.MethodPatch, "/1", `{"age":21,"version":3}`, handler, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("version in body: status = %d, want 200: %s", w.Code, w.Body)
+	}
+}
+
+func TestBatchUpdateHandlerValidatesUpdates(t *testing.T) {
+	type updates struct {
+		Age     *int   `json:"age" binding:"omitempty,gte=0"`
+		Version *int64 `json:"version"`
+	}
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.rowsAffected, rec.result = 1, existingUser
+	handler := GenericBatchUpdateHandler[testUser, updates](db)
+
+	bad := map[string]string{
+		"binding rule": `[{"id":1,"updates":{"age":21,"version":3}},{"id":2,"updates":{"age":-5,"version":3}}]`,
+		"no updates":   `[{"id":1,"updates":{}}]`,
+	}
+	for name, body := range bad {
+		w := serve("/batch-update", http.MethodPost, "/batch-update", body, handler, nil)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400: %s", name, w.Code, w.Body)
+		}
+		if sql := rec.last("UPDATE"); sql != "" {
+			t.Fatalf("%s: update was executed: %s", name, sql)
+		}
+	}
+
+	w := serve("/batch-update", http.MethodPost, "/batch-update", `[{"id":1,"updates":{"age":21,"version":3}}]`, handler, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if sql := rec.last("UPDATE"); !strings.Contains(sql, "`age`=?") {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+}
@@ -0,0 +1,156 @@
+package dbkit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testUser 测试用实体
+type testUser struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Name     string `json:"name"`
+	Age      int    `json:"age" ops:"eq,gte,lt,between"`
+	TenantID uint   `json:"tenant_id" tenant:"true"`
+	Version  int    `json:"version" gorm:"default:1" version:"true"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (testUser) TableName() string { return "user" }
+
+var testConfig = &gorm.Config{
+	DisableAutomaticPing:   true,
+	SkipDefaultTransaction: true,
+	Logger:                 logger.Discard,
+}
+
+// dryRunDB 返回只生成 SQL、不连接数据库的 MySQL 会话
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "user:pass@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), testConfig)
+	if err != nil {
+		t.Fatalf("open dry run db: %v", err)
+	}
+	return db.Session(&gorm.Session{DryRun: true})
+}
+
+// toSQL 生成 fn 对应的 SQL（参数已内联）
+func toSQL(t *testing.T, db *gorm.DB, fn func(tx *gorm.DB) *gorm.DB) string {
+	t.Helper()
+	return db.ToSQL(fn)
+}
+
+// recorder 记录执行的 SQL，查询返回 result 的结果（未设置时为空），写操作返回 rowsAffected
+type recorder struct {
+	mu           sync.Mutex
+	statements   []string
+	rowsAffected int64
+	result       func(query string) (columns []string, rows [][]driver.Value)
+}
+
+// recordDB 返回通过 recorder 执行 SQL 的 MySQL 会话，dsn 用于设置连接参数（如 loc）
+func recordDB(t *testing.T, dsn string) (*gorm.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{rowsAffected: 1}
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       dsn,
+		Conn:                      sql.OpenDB(rec),
+		SkipInitializeWithVersion: true,
+	}), testConfig)
+	if err != nil {
+		t.Fatalf("open recording db: %v", err)
+	}
+	return db, rec
+}
+
+// SQL 返回已执行的语句（参数以 ? 占位，值追加在末尾）
+func (r *recorder) SQL() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.statements...)
+}
+
+// last 返回最后一条包含 keyword 的语句
+func (r *recorder) last(keyword string) string {
+	statements := r.SQL()
+	for i := len(statements) - 1; i >= 0; i-- {
+		if strings.Contains(statements[i], keyword) {
+			return statements[i]
+		}
+	}
+	return ""
+}
+
+func (r *recorder) record(query string, args []driver.NamedValue) {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprint(arg.Value)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, query+" | "+strings.Join(values, ", "))
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return &recordConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return nil }
+
+type recordConn struct{ r *recorder }
+
+func (c *recordConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordConn) Close() error                        { return nil }
+func (c *recordConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *recordConn) Commit() error                       { return nil }
+func (c *recordConn) Rollback() error                     { c.r.record("ROLLBACK", nil); return nil }
+
+func (c *recordConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.r.record(query, args)
+	if c.r.result == nil {
+		return &resultRows{}, nil
+	}
+	columns, rows := c.r.result(query)
+	return &resultRows{columns: columns, rows: rows}, nil
+}
+
+func (c *recordConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query, args)
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	return execResult(c.r.rowsAffected), nil
+}
+
+// execResult 写操作的结果，自增主键总是 1
+type execResult int64
+
+func (r execResult) LastInsertId() (int64, error) { return 1, nil }
+func (r execResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+type resultRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *resultRows) Columns() []string { return r.columns }
+func (r *resultRows) Close() error      { return nil }
+
+func (r *resultRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,360 @@
+package dbkit
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/driver/mysql"
+)
+
+// DateHistogram 按时间间隔分桶统计，数据库中的时间按连接参数 loc 的时区解析（MySQL，默认 UTC）
+type DateHistogram struct {
+	Field    string     `json:"field"`     // 时间字段
+	Interval string     `json:"interval"`  // hour, day, week（周一开始）, month, year
+	TimeZone string     `json:"time_zone"` // 分桶使用的时区，如 Asia/Shanghai，默认 UTC
+	From     *time.Time `json:"from"`      // 补零范围的起点，默认取第一个有数据的桶
+	To       *time.Time `json:"to"`        // 补零范围的终点，默认取最后一个有数据的桶，设置了 From 且没有数据时取当前时间
+}
+
+// NumericHistogram 数值字段按固定宽度分桶
+type NumericHistogram struct {
+	Field    string  `json:"field"`
+	Interval float64 `json:"interval"` // 桶宽度，须大于 0
+}
+
+// HistogramBucket 数值分桶结果，Key 为桶的下界
+type HistogramBucket struct {
+	Key   float64 `json:"key"`
+	Count int64   `json:"count"`
+}
+
+// bucketLayouts 各间隔的桶 key 格式（Go 时间格式）
+var bucketLayouts = map[string]string{
+	"hour":  "2006-01-02 15:00:00",
+	"day":   "2006-01-02",
+	"week":  "2006-01-02",
+	"month": "2006-01-02",
+	"year":  "2006-01-02",
+}
+
+// maxBuckets 补零时允许的最大桶数，防止范围过大
+const maxBuckets = 10000
+
+// dateHistogram 执行时间分桶统计，空桶补零
+func (qb *QueryBuilder) dateHistogram(config StatsConfig) ([]QueryStats, error) {
+	h := config.DateHistogram
+	if _, ok := bucketLayouts[h.Interval]; !ok {
+		return nil, fmt.Errorf("%w: unsupported interval %q", ErrInvalidAggregate, h.Interval)
+	}
+
+	loc := time.UTC
+	if h.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(h.TimeZone); err != nil {
+			return nil, fmt.Errorf("%w: invalid time zone %q", ErrInvalidAggregate, h.TimeZone)
+		}
+	}
+
+	column, err := qb.quoteColumn(h.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, vars, err := qb.bucketExpr(column, h.Interval, qb.storageLocation(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, fields, err := qb.statsAggregates(config)
+	if err != nil {
+		return nil, err
+	}
+
+	aggSelects, aggColumns, err := qb.selectAggregates(aggregates)
+	if err != nil {
+		return nil, err
+	}
+	selects := append([]string{bucket + " AS " + qb.quote("bucket")}, aggSelects...)
+	columns := append([]groupColumn{{alias: "bucket", typ: reflect.TypeOf("")}}, aggColumns...)
+
+	// 只统计起止范围所在的桶
+	if h.From != nil {
+		qb.db = qb.db.Where(column+" >= ?", truncateBucket(h.From.In(loc), h.Interval))
+	}
+	if h.To != nil {
+		qb.db = qb.db.Where(column+" < ?", nextBucket(truncateBucket(h.To.In(loc), h.Interval), h.Interval))
+	}
+
+	qb.db = qb.db.Select(strings.Join(selects, ", "), vars...).
+		Group(qb.quote("bucket")).
+		Order(qb.quote("bucket"))
+
+	rows, err := scanGroupRows(qb.GetDB(), columns)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]QueryStats, len(rows))
+	var keys []string
+	for _, row := range rows {
+		key, _ := row["bucket"].(string)
+		stats := statsFromRow(row, fields)
+		stats.Key = key
+		found[key] = stats
+		keys = append(keys, key)
+	}
+
+	return fillBuckets(h, loc, keys, found, config.SumFields)
+}
+
+// bucketExpr 生成将时间列从存储时区 source 转换到分桶时区 target 后的桶 key 表达式
+func (qb *QueryBuilder) bucketExpr(column, interval string, source, target *time.Location) (string, []interface{}, error) {
+	switch qb.db.Dialector.Name() {
+	case "mysql":
+		from, err := mysqlZone(source)
+		if err != nil {
+			return "", nil, err
+		}
+		to, err := mysqlZone(target)
+		if err != nil {
+			return "", nil, err
+		}
+
+		shifted, zones := column, []interface{}{}
+		if from != to {
+			shifted, zones = fmt.Sprintf("CONVERT_TZ(%s, ?, ?)", column), []interface{}{from, to}
+		}
+		switch interval {
+		case "hour":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:00:00')", shifted), zones, nil
+		case "day":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", shifted), zones, nil
+		case "week":
+			return fmt.Sprintf("DATE_FORMAT(DATE_SUB(%s, INTERVAL WEEKDAY(%s) DAY), '%%Y-%%m-%%d')", shifted, shifted), append(zones, zones...), nil
+		case "month":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-01')", shifted), zones, nil
+		case "year":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-01-01')", shifted), zones, nil
+		}
+	case "sqlite":
+		// SQLite 没有时区库，只支持固定偏移的时区
+		offset, ok := fixedOffset(target)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: time zone %q observes daylight saving time, which sqlite cannot convert", ErrInvalidAggregate, target)
+		}
+		modifier := fmt.Sprintf("%+d minutes", offset/60)
+		switch interval {
+		case "hour":
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:00:00', %s, ?)", column), []interface{}{modifier}, nil
+		case "day":
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s, ?)", column), []interface{}{modifier}, nil
+		case "week":
+			return fmt.Sprintf("date(%s, ?, '-6 days', 'weekday 1')", column), []interface{}{modifier}, nil
+		case "month":
+			return fmt.Sprintf("strftime('%%Y-%%m-01', %s, ?)", column), []interface{}{modifier}, nil
+		case "year":
+			return fmt.Sprintf("strftime('%%Y-01-01', %s, ?)", column), []interface{}{modifier}, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w: date histogram is not supported by %s", ErrInvalidAggregate, qb.db.Dialector.Name())
+}
+
+// fillBuckets 在起止范围内按间隔补齐空桶。桶按 loc 中的墙上时间以 UTC 步进，
+// 夏令时结束时重复的小时只产生一个桶，开始时跳过的小时不产生桶
+func fillBuckets(h *DateHistogram, loc *time.Location, keys []string, found map[string]QueryStats, sumFields []string) ([]QueryStats, error) {
+	layout := bucketLayouts[h.Interval]
+
+	var start, end time.Time
+	if len(keys) > 0 {
+		start, _ = time.ParseInLocation(layout, keys[0], time.UTC)
+		end, _ = time.ParseInLocation(layout, keys[len(keys)-1], time.UTC)
+	}
+	if h.From != nil {
+		start = truncateBucket(wallClock(h.From.In(loc)), h.Interval)
+		if len(keys) == 0 && h.To == nil {
+			end = truncateBucket(wallClock(time.Now().In(loc)), h.Interval)
+		}
+	}
+	if h.To != nil {
+		end = truncateBucket(wallClock(h.To.In(loc)), h.Interval)
+	}
+	if start.IsZero() || end.IsZero() {
+		return []QueryStats{}, nil
+	}
+
+	buckets := make([]QueryStats, 0)
+	for t := start; !t.After(end); t = nextBucket(t, h.Interval) {
+		if h.Interval == "hour" && !existsIn(t, loc) {
+			continue
+		}
+		if len(buckets) >= maxBuckets {
+			return nil, fmt.Errorf("%w: date histogram exceeds %d buckets", ErrInvalidAggregate, maxBuckets)
+		}
+
+		key := t.Format(layout)
+		if stats, ok := found[key]; ok {
+			buckets = append(buckets, stats)
+			continue
+		}
+
+		empty := QueryStats{Key: key, Sum: make(map[string]float64)}
+		for _, field := range sumFields {
+			empty.Sum[field] = 0
+		}
+		buckets = append(buckets, empty)
+	}
+
+	return buckets, nil
+}
+
+// numericHistogram 按 FLOOR(值 / 宽度) 分桶计数，最小与最大桶之间的空桶补零
+func (qb *QueryBuilder) numericHistogram(h NumericHistogram) ([]HistogramBucket, error) {
+	if h.Interval <= 0 {
+		return nil, fmt.Errorf("%w: histogram interval must be positive", ErrInvalidAggregate)
+	}
+
+	column, err := qb.quoteColumn(h.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite 未必编译了数学函数，用 CAST 截断后修正负数实现向下取整
+	bucket, vars := fmt.Sprintf("FLOOR(%s / ?)", column), []interface{}{h.Interval}
+	if qb.db.Dialector.Name() == "sqlite" {
+		bucket = fmt.Sprintf("(CAST(%[1]s / ? AS INTEGER) - (%[1]s / ? < CAST(%[1]s / ? AS INTEGER)))", column)
+		vars = []interface{}{h.Interval, h.Interval, h.Interval}
+	}
+
+	qb.db = qb.db.Select(bucket+" AS "+qb.quote("bucket")+", COUNT(*) AS "+qb.quote("count"), vars...).
+		Where(column + " IS NOT NULL").
+		Group(qb.quote("bucket")).
+		Order(qb.quote("bucket"))
+
+	rows, err := scanGroupRows(qb.GetDB(), []groupColumn{{alias: "bucket", typ: int64Type}, {alias: "count", typ: int64Type}})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []HistogramBucket{}, nil
+	}
+
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		index, _ := toInt64(row["bucket"])
+		counts[index], _ = toInt64(row["count"])
+	}
+
+	first, _ := toInt64(rows[0]["bucket"])
+	last, _ := toInt64(rows[len(rows)-1]["bucket"])
+	if last-first >= maxBuckets {
+		return nil, fmt.Errorf("%w: histogram exceeds %d buckets", ErrInvalidAggregate, maxBuckets)
+	}
+
+	buckets := make([]HistogramBucket, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		buckets = append(buckets, HistogramBucket{Key: float64(i) * h.Interval, Count: counts[i]})
+	}
+	return buckets, nil
+}
+
+// truncateBucket 将时间截断到所在桶的起点
+func truncateBucket(t time.Time, interval string) time.Time {
+	y, m, d := t.Date()
+	switch interval {
+	case "hour":
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case "week":
+		weekday := (int(t.Weekday()) + 6) % 7 // 周一为 0
+		return time.Date(y, m, d-weekday, 0, 0, 0, 0, t.Location())
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case "year":
+		return time.Date(y, 1, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// wallClock 将时间的墙上时间表示为 UTC 时间
+func wallClock(t time.Time) time.Time {
+	y, m, d := t.Date()
+	hour, min, sec := t.Clock()
+	return time.Date(y, m, d, hour, min, sec, t.Nanosecond(), time.UTC)
+}
+
+// existsIn 墙上时间 t（UTC 表示）所在的小时在 loc 中是否存在，夏令时开始时跳过的小时不存在
+func existsIn(t time.Time, loc *time.Location) bool {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc).Hour() == t.Hour()
+}
+
+func nextBucket(t time.Time, interval string) time.Time {
+	switch interval {
+	case "hour":
+		return t.Add(time.Hour)
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	case "year":
+		return t.AddDate(1, 0, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// storageLocation 数据库中时间值所在的时区：MySQL 取连接参数 loc，其他方言按 UTC 处理
+func (qb *QueryBuilder) storageLocation() *time.Location {
+	if d, ok := qb.db.Dialector.(*mysql.Dialector); ok && d.DSNConfig != nil && d.DSNConfig.Loc != nil {
+		return d.DSNConfig.Loc
+	}
+	return time.UTC
+}
+
+// mysqlZone 时区对应的 CONVERT_TZ 参数：固定偏移的时区用 '+08:00'，
+// 有夏令时的时区用 IANA 名称，逐行按当时的偏移转换（需要 MySQL 已加载时区表）
+func mysqlZone(loc *time.Location) (string, error) {
+	if offset, ok := fixedOffset(loc); ok {
+		return fmt.Sprintf("%+03d:%02d", offset/3600, abs(offset%3600)/60), nil
+	}
+
+	name := loc.String()
+	if name == "Local" {
+		name = localZoneName()
+	}
+	if name == "" || name == "Local" {
+		return "", fmt.Errorf("%w: cannot resolve the name of the local time zone", ErrInvalidAggregate)
+	}
+	return name, nil
+}
+
+// fixedOffset 时区在当年内没有夏令时切换时返回其偏移（秒）
+func fixedOffset(loc *time.Location) (int, bool) {
+	year := time.Now().Year()
+	_, winter := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, summer := time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	return winter, winter == summer
+}
+
+// localZoneName 本地时区的 IANA 名称：TZ 环境变量优先，其次 /etc/localtime 指向的时区文件
+func localZoneName() string {
+	if tz, ok := os.LookupEnv("TZ"); ok {
+		return strings.TrimPrefix(tz, ":")
+	}
+	target, err := os.Readlink("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return name
+	}
+	return ""
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
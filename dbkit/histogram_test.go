@@ -0,0 +1,161 @@
+package dbkit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateHistogramTimeZones(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	// 2024-03-10 美东进入夏令时，当天 0 点为 -05:00，次日 0 点为 -04:00
+	from := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		dsn      string
+		timeZone string
+		want     []string
+		notWant  string
+	}{
+		{
+			name:     "utc storage",
+			dsn:      "user:pass@tcp(127.0.0.1:1)/test?parseTime=true",
+			timeZone: "America/New_York",
+			want: []string{
+				"DATE_FORMAT(CONVERT_TZ(`created_at`, ?, ?), '%Y-%m-%d')",
+				"+00:00, America/New_York",
+				"2024-03-10 00:00:00 -0500 EST, 2024-03-11 00:00:00 -0400 EDT",
+			},
+		},
+		{
+			name:     "fixed offset storage",
+			dsn:      "user:pass@tcp(127.0.0.1:1)/test?parseTime=true&loc=Asia%2FShanghai",
+			timeZone: "America/New_York",
+			want:     []string{"+08:00, America/New_York"},
+		},
+		{
+			name:     "same zone",
+			dsn:      "user:pass@tcp(127.0.0.1:1)/test?parseTime=true&loc=Asia%2FShanghai",
+			timeZone: "Asia/Shanghai",
+			want:     []string{"DATE_FORMAT(`created_at`, '%Y-%m-%d')"},
+			notWant:  "CONVERT_TZ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := recordDB(t, tt.dsn)
+			h := &DateHistogram{Field: "created_at", Interval: "day", TimeZone: tt.timeZone, From: &from, To: &to}
+			if _, err := NewQueryBuilder(db.Model(&testUser{})).dateHistogram(StatsConfig{DateHistogram: h}); err != nil {
+				t.Fatalf("dateHistogram: %v", err)
+			}
+
+			sql := rec.last("GROUP BY")
+			for _, want := range tt.want {
+				if !strings.Contains(sql, want) {
+					t.Errorf("sql %q does not contain %q", sql, want)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(sql, tt.notWant) {
+				t.Errorf("sql %q contains %q", sql, tt.notWant)
+			}
+		})
+	}
+}
+
+func TestFillBucketsAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	from := time.Date(2024, 3, 9, 12, 0, 0, 0, newYork)
+	to := time.Date(2024, 3, 11, 12, 0, 0, 0, newYork)
+	h := &DateHistogram{Interval: "hour", From: &from, To: &to}
+
+	buckets, err := fillBuckets(h, newYork, nil, map[string]QueryStats{}, nil)
+	if err != nil {
+		t.Fatalf("fillBuckets: %v", err)
+	}
+	// 夏令时当天只有 23 小时
+	if want := 12 + 23 + 13; len(buckets) != want {
+		t.Fatalf("got %d buckets, want %d", len(buckets), want)
+	}
+	if buckets[0].Key != "2024-03-09 12:00:00" || buckets[len(buckets)-1].Key != "2024-03-11 12:00:00" {
+		t.Fatalf("unexpected bounds %q .. %q", buckets[0].Key, buckets[len(buckets)-1].Key)
+	}
+}
+
+func TestFillBucketsDSTEndHasNoDuplicates(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	// 夏令时结束时 01:00 出现两次，数据库中合并为同一个桶
+	from := time.Date(2024, 11, 3, 0, 0, 0, 0, newYork)
+	to := time.Date(2024, 11, 3, 3, 0, 0, 0, newYork)
+	h := &DateHistogram{Interval: "hour", From: &from, To: &to}
+
+	buckets, err := fillBuckets(h, newYork, []string{"2024-11-03 01:00:00"}, map[string]QueryStats{
+		"2024-11-03 01:00:00": {Key: "2024-11-03 01:00:00", Count: 2},
+	}, nil)
+	if err != nil {
+		t.Fatalf("fillBuckets: %v", err)
+	}
+
+	var keys []string
+	for _, b := range buckets {
+		keys = append(keys, b.Key)
+	}
+	want := []string{"2024-11-03 00:00:00", "2024-11-03 01:00:00", "2024-11-03 02:00:00", "2024-11-03 03:00:00"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	if buckets[1].Count != 2 {
+		t.Fatalf("count = %d, want 2", buckets[1].Count)
+	}
+}
+
+func TestFillBucketsFromWithoutRows(t *testing.T) {
+	today := time.Now().UTC().Format("2006-01-02")
+	from := time.Now().AddDate(0, 0, -2)
+	h := &DateHistogram{Interval: "day", From: &from}
+
+	buckets, err := fillBuckets(h, time.UTC, nil, map[string]QueryStats{}, []string{"amount"})
+	if err != nil {
+		t.Fatalf("fillBuckets: %v", err)
+	}
+	if len(buckets) < 3 {
+		t.Fatalf("got %d buckets, want buckets up to today", len(buckets))
+	}
+	if last := buckets[len(buckets)-1]; last.Key < today || last.Count != 0 || last.Sum["amount"] != 0 {
+		t.Fatalf("unexpected last bucket %+v, want an empty bucket for %s", last, today)
+	}
+}
+
+func TestMySQLZone(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		loc  *time.Location
+		want string
+	}{
+		{loc: time.UTC, want: "+00:00"},
+		{loc: shanghai, want: "+08:00"},
+		{loc: time.FixedZone("", -(3*3600 + 1800)), want: "-03:30"},
+	}
+	for _, tt := range tests {
+		if got, err := mysqlZone(tt.loc); err != nil || got != tt.want {
+			t.Errorf("mysqlZone(%v) = %q, %v, want %q", tt.loc, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package dbkit
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IDFilter 按主键操作（GET/PATCH/PUT/DELETE /:id 与批量接口）时传给钩子的过滤条件
+type IDFilter struct {
+	IDs []interface{} `json:"ids"`
+}
+
+// Hooks 通用处理器的生命周期钩子，任一钩子返回错误即中止请求：
+// Abort 或 *APIError 按其状态码与错误码响应，其余错误按 TranslateError 处理。
+// filters 为请求中的过滤结构体，按主键操作时为 IDFilter。
+type Hooks[T any] struct {
+	BeforeQuery func(c *gin.Context, req QueryRequest) error // 查询、统计前，可校验或改写请求
+	AfterQuery  func(c *gin.Context, rows []T) error         // 查询后，可修改结果（返回 DTO 的查询不触发）
+
+	BeforeCreate func(c *gin.Context, entity *T) error // 创建前，批量创建时逐条调用
+	AfterCreate  func(c *gin.Context, entity *T) error
+
+	BeforeUpdate func(c *gin.Context, filters interface{}, updates map[string]interface{}) error // 可直接修改 updates
+	AfterUpdate  func(c *gin.Context, filters interface{}, affected int64) error
+
+	BeforeReplace func(c *gin.Context, id interface{}, entity *T) error // PUT /:id 整体替换前
+	AfterReplace  func(c *gin.Context, id interface{}, entity *T) error
+
+	BeforeDelete func(c *gin.Context, filters interface{}) error // 删除、永久删除（purge）前
+	AfterDelete  func(c *gin.Context, filters interface{}, affected int64) error
+
+	BeforeRestore func(c *gin.Context, id interface{}) error // 恢复软删除的记录前
+	AfterRestore  func(c *gin.Context, entity *T) error
+
+	// AllowDeleted 访问已删除记录（include_deleted/only_deleted 查询、restore、purge）前的权限检查，
+	// 未注册时拒绝访问（403）
+	AllowDeleted func(c *gin.Context) error
+}
+
+// WithHooks 为通用处理器注册钩子，可多次使用，按注册顺序执行
+func WithHooks[T any](hooks Hooks[T]) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, hooks)
+	}
+}
+
+// WithGeneratedID 创建前为每条记录生成32位UUID，idSetter 负责赋值
+func WithGeneratedID[T any](idSetter func(*T, string)) Option {
+	return WithHooks(Hooks[T]{
+		BeforeCreate: func(c *gin.Context, entity *T) error {
+			idSetter(entity, generateUUID32())
+			return nil
+		},
+	})
+}
+
+// hookChain 已注册的同一实体类型的钩子
+type hookChain[T any] []Hooks[T]
+
+// hooksOf 取出实体类型 T 的钩子，类型不匹配时 panic，注册路由时即可发现
+func hooksOf[T any](o *options) hookChain[T] {
+	chain := make(hookChain[T], 0, len(o.hooks))
+	for _, h := range o.hooks {
+		hooks, ok := h.(Hooks[T])
+		if !ok {
+			var model T
+			panic(fmt.Sprintf("dbkit: hooks of type %T cannot be used with %T", h, model))
+		}
+		chain = append(chain, hooks)
+	}
+	return chain
+}
+
+func (h hookChain[T]) beforeQuery(c *gin.Context, req QueryRequest) error {
+	if requestsDeleted(req) {
+		if err := h.allowDeleted(c); err != nil {
+			return err
+		}
+	}
+	for _, hooks := range h {
+		if hooks.BeforeQuery != nil {
+			if err := hooks.BeforeQuery(c, req); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) afterQuery(c *gin.Context, rows []T) error {
+	for _, hooks := range h {
+		if hooks.AfterQuery != nil {
+			if err := hooks.AfterQuery(c, rows); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) beforeCreate(c *gin.Context, entity *T) error {
+	for _, hooks := range h {
+		if hooks.BeforeCreate != nil {
+			if err := hooks.BeforeCreate(c, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) afterCreate(c *gin.Context, entity *T) error {
+	for _, hooks := range h {
+		if hooks.AfterCreate != nil {
+			if err := hooks.AfterCreate(c, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) beforeUpdate(c *gin.Context, filters interface{}, updates map[string]interface{}) error {
+	for _, hooks := range h {
+		if hooks.BeforeUpdate != nil {
+			if err := hooks.BeforeUpdate(c, filters, updates); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) afterUpdate(c *gin.Context, filters interface{}, affected int64) error {
+	for _, hooks := range h {
+		if hooks.AfterUpdate != nil {
+			if err := hooks.AfterUpdate(c, filters, affected); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) beforeReplace(c *gin.Context, id interface{}, entity *T) error {
+	for _, hooks := range h {
+		if hooks.BeforeReplace != nil {
+			if err := hooks.BeforeReplace(c, id, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) afterReplace(c *gin.Context, id interface{}, entity *T) error {
+	for _, hooks := range h {
+		if hooks.AfterReplace != nil {
+			if err := hooks.AfterReplace(c, id, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) beforeDelete(c *gin.Context, filters interface{}) error {
+	for _, hooks := range h {
+		if hooks.BeforeDelete != nil {
+			if err := hooks.BeforeDelete(c, filters); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) afterDelete(c *gin.Context, filters interface{}, affected int64) error {
+	for _, hooks := range h {
+		if hooks.AfterDelete != nil {
+			if err := hooks.AfterDelete(c, filters, affected); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) beforeRestore(c *gin.Context, id interface{}) error {
+	for _, hooks := range h {
+		if hooks.BeforeRestore != nil {
+			if err := hooks.BeforeRestore(c, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h hookChain[T]) afterRestore(c *gin.Context, entity *T) error {
+	for _, hooks := range h {
+		if hooks.AfterRestore != nil {
+			if err := hooks.AfterRestore(c, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// allowDeleted 所有 AllowDeleted 钩子都放行才允许访问已删除的记录，未注册时拒绝
+func (h hookChain[T]) allowDeleted(c *gin.Context) error {
+	registered := false
+	for _, hooks := range h {
+		if hooks.AllowDeleted != nil {
+			registered = true
+			if err := hooks.AllowDeleted(c); err != nil {
+				return err
+			}
+		}
+	}
+	if !registered {
+		return Abort(http.StatusForbidden, "access to deleted records is not allowed")
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package dbkit
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 var ErrFilterRequired = errors.New("filters required")
@@ -16,9 +18,20 @@ type QueryRequest interface {
 }
 
 type BaseQueryRequest[F any, O any] struct {
-	Page    *Page `json:"page"`
-	Filters F     `json:"filters"`
-	Orders  O     `json:"orders"`
+	Page    *Page         `json:"page"`
+	Filters F             `json:"filters"`
+	Orders  O             `json:"orders"`
+	Where   DynamicFilter `json:"where,omitempty"`   // 动态过滤条件，与 Filters 为 AND 关系
+	Fields  []string      `json:"fields,omitempty"`  // 返回的字段（json 名或列名），为空时返回全部字段
+	Include []Include     `json:"include,omitempty"` // 一并加载的关联
+
+	IncludeDeleted bool `json:"include_deleted,omitempty"` // 同时查询已软删除的记录
+	OnlyDeleted    bool `json:"only_deleted,omitempty"`    // 只查询已软删除的记录
+}
+
+// DynamicFilterRequest 携带动态过滤条件的查询请求
+type DynamicFilterRequest interface {
+	GetDynamicFilter() DynamicFilter
 }
 
 func (r *BaseQueryRequest[F, O]) GetPage() interface{} {
@@ -33,40 +46,139 @@ func (r *BaseQueryRequest[F, O]) GetOrders() interface{} {
 	return r.Orders
 }
 
-func Query[T any](db *gorm.DB, req QueryRequest) ([]T, int64, error) {
-	var results []T
-	var model T
+func (r *BaseQueryRequest[F, O]) GetDynamicFilter() DynamicFilter {
+	return r.Where
+}
 
-	qb := NewQueryBuilder(db)
-	qb.db = qb.db.Model(&model)
+func (r *BaseQueryRequest[F, O]) GetFields() []string {
+	return r.Fields
+}
+
+func (r *BaseQueryRequest[F, O]) GetIncludes() []Include {
+	return r.Include
+}
+
+// applyRequestFilters 应用请求中的结构体过滤条件、动态过滤条件与软删除范围
+func (qb *QueryBuilder) applyRequestFilters(req QueryRequest) *QueryBuilder {
+	qb.applyDeletedScope(req)
 	qb.ApplyFilters(req.GetFilters())
-	qb.ApplyOrders(req.GetOrders())
-	qb.ApplyPagination(req.GetPage())
+	if r, ok := req.(DynamicFilterRequest); ok {
+		qb.ApplyDynamicFilter(r.GetDynamicFilter())
+	}
+	return qb
+}
 
-	count, err := qb.QueryWithCount(&results)
+// PageMeta 分页元信息
+type PageMeta struct {
+	Total      int64
+	TotalMode  TotalMode // 实际使用的统计方式，TotalNone 时 Total 无意义
+	HasMore    bool
+	NextCursor string
+	PrevCursor string
+}
+
+// PageResult 分页查询结果
+type PageResult[T any] struct {
+	Data []T
+	PageMeta
+}
+
+func Query[T any](db *gorm.DB, req QueryRequest) ([]T, int64, error) {
+	result, err := QueryPage[T](db, req)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return results, count, nil
+	return result.Data, result.Total, nil
 }
 
 func QueryTo[T any, R any](db *gorm.DB, req QueryRequest) ([]R, int64, error) {
-	var results []R
+	result, err := QueryPageTo[T, R](db, req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return result.Data, result.Total, nil
+}
+
+// QueryPage 分页查询，同时支持页码分页与游标分页
+func QueryPage[T any](db *gorm.DB, req QueryRequest, opts ...Option) (*PageResult[T], error) {
+	return queryPage[T, T](db, req, newOptions(opts))
+}
+
+// QueryPageTo 分页查询（映射到DTO），同时支持页码分页与游标分页
+func QueryPageTo[T any, R any](db *gorm.DB, req QueryRequest, opts ...Option) (*PageResult[R], error) {
+	return queryPage[T, R](db, req, newOptions(opts))
+}
+
+func queryPage[T any, R any](db *gorm.DB, req QueryRequest, o *options) (*PageResult[R], error) {
 	var model T
+	result := &PageResult[R]{}
+	page, _ := req.GetPage().(*Page)
+	if page != nil && page.Cursor != nil && page.PageSize <= 0 {
+		return nil, fmt.Errorf("%w: page_size must be positive for cursor pagination", ErrInvalidPage)
+	}
+
+	if r, ok := req.(IncludeRequest); ok && len(r.GetIncludes()) > 0 && reflect.TypeOf(result.Data).Elem() != reflect.TypeOf(model) {
+		return nil, fmt.Errorf("%w: include is not supported when mapping to %T", ErrInvalidInclude, *new(R))
+	}
 
 	qb := NewQueryBuilder(db)
 	qb.db = qb.db.Model(&model)
-	qb.ApplyFilters(req.GetFilters())
-	qb.ApplyOrders(req.GetOrders())
-	qb.ApplyPagination(req.GetPage())
+	qb.applyRequestFilters(req)
+	qb.applyIncludes(req)
 
-	count, err := qb.QueryWithCount(&results)
+	total, mode, err := countTotal(qb, resolveTotalMode(page, o.totalMode))
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	result.Total, result.TotalMode = total, mode
+
+	// 映射到 DTO 时返回字段按 DTO 的字段解析
+	var dest *schema.Schema
+	if reflect.TypeOf(result.Data).Elem() != reflect.TypeOf(model) {
+		if dest, err = parseSchema(db, new(R)); err != nil {
+			return nil, err
+		}
+	}
+	qb.applyFields(req, dest)
+
+	if page.IsCursor() {
+		if err := queryCursor[T, R](qb, page, req.GetOrders(), result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	qb.ApplyOrders(req.GetOrders())
+
+	if !page.IsValid() {
+		if err := qb.Query(&result.Data); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if mode == TotalExact {
+		qb.ApplyPagination(page)
+		if err := qb.Query(&result.Data); err != nil {
+			return nil, err
+		}
+		result.HasMore = int64(page.GetOffset()+len(result.Data)) < result.Total
+		return result, nil
+	}
+
+	// 不依赖总数时多取一行判断是否还有下一页
+	qb.db = qb.db.Offset(page.GetOffset()).Limit(page.GetLimit() + 1)
+	if err := qb.Query(&result.Data); err != nil {
+		return nil, err
+	}
+	if len(result.Data) > page.PageSize {
+		result.Data = result.Data[:page.PageSize]
+		result.HasMore = true
 	}
 
-	return results, count, nil
+	return result, nil
 }
 
 func First[T any](db *gorm.DB, req QueryRequest) (*T, error) {
@@ -75,8 +187,10 @@ func First[T any](db *gorm.DB, req QueryRequest) (*T, error) {
 
 	qb := NewQueryBuilder(db)
 	qb.db = qb.db.Model(&model)
-	qb.ApplyFilters(req.GetFilters())
+	qb.applyRequestFilters(req)
 	qb.ApplyOrders(req.GetOrders())
+	qb.applyIncludes(req)
+	qb.applyFields(req, nil)
 
 	if err := qb.GetDB().First(&out).Error; err != nil {
 		return nil, err
@@ -85,35 +199,268 @@ func First[T any](db *gorm.DB, req QueryRequest) (*T, error) {
 	return &out, nil
 }
 
+// primaryKey 返回模型的主键字段
+func (qb *QueryBuilder) primaryKey() (*schema.Field, error) {
+	sch := qb.modelSchema()
+	if sch == nil || sch.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("%T has no primary key", qb.db.Statement.Model)
+	}
+	return sch.PrioritizedPrimaryField, nil
+}
+
+// whereID 追加主键条件
+func (qb *QueryBuilder) whereID(id interface{}) error {
+	pk, err := qb.primaryKey()
+	if err != nil {
+		return err
+	}
+	qb.db = qb.db.Where(qb.quote(pk.DBName)+" = ?", id)
+	return nil
+}
+
+// GetByID 按主键获取单条记录，不存在时返回 gorm.ErrRecordNotFound
+func GetByID[T any](db *gorm.DB, id interface{}) (*T, error) {
+	var out T
+
+	qb := NewQueryBuilder(db.Model(&out))
+	if err := qb.whereID(id); err != nil {
+		return nil, err
+	}
+
+	if err := qb.GetDB().First(&out).Error; err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// UpdateByID 按主键部分更新，updates 的键可为 json 名或列名，不存在时返回 gorm.ErrRecordNotFound。
+// 模型有版本字段时 updates 中必须带版本号（否则返回 ErrVersionRequired），版本不一致返回 ErrVersionConflict。
+func UpdateByID[T any](db *gorm.DB, id interface{}, updates map[string]interface{}) (*T, error) {
+	if auditEnabled(db) {
+		var out *T
+		err := auditChanges[T](db, AuditUpdate, idScope(id), func(tx *gorm.DB) (err error) {
+			out, err = UpdateByID[T](tx, id, updates)
+			return err
+		})
+		return out, err
+	}
+
+	var model T
+	qb := NewQueryBuilder(db.Model(&model))
+	if err := qb.whereID(id); err != nil {
+		return nil, err
+	}
+
+	columns, conditioned, err := qb.versionedUpdates(updates)
+	if err != nil {
+		return nil, err
+	}
+
+	res := qb.GetDB().Updates(columns)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if conditioned && res.RowsAffected == 0 {
+		return nil, versionConflict[T](db, id)
+	}
+
+	// 值未变化时 RowsAffected 为 0，重新查询以区分记录不存在
+	return GetByID[T](db, id)
+}
+
+// ReplaceByID 按主键整体替换，除主键、创建时间、软删除与租户字段外的字段均以 entity 为准，不存在时返回 gorm.ErrRecordNotFound。
+// 模型有版本字段时 entity 必须带当前版本号，版本不一致返回 ErrVersionConflict。
+func ReplaceByID[T any](db *gorm.DB, id interface{}, entity *T) (*T, error) {
+	if auditEnabled(db) {
+		var out *T
+		err := auditChanges[T](db, AuditUpdate, idScope(id), func(tx *gorm.DB) (err error) {
+			out, err = ReplaceByID[T](tx, id, entity)
+			return err
+		})
+		return out, err
+	}
+
+	qb := NewQueryBuilder(db.Model(new(T)))
+	if err := qb.whereID(id); err != nil {
+		return nil, err
+	}
+
+	version := versionField(qb.modelSchema())
+	if version != nil {
+		rv := reflect.ValueOf(entity).Elem()
+		expected, isZero := version.ValueOf(db.Statement.Context, rv)
+		n, ok := toInt64(expected)
+		if isZero || !ok {
+			name := jsonName(version)
+			return nil, &ValidationError{Errors: []FieldError{{Field: name, Rule: "required", Message: name + " is required"}}}
+		}
+		qb.db = qb.db.Where(qb.quote(version.DBName)+" = ?", expected)
+		if err := version.Set(db.Statement.Context, rv, n+1); err != nil {
+			return nil, err
+		}
+	}
+
+	omit := []string{}
+	for _, field := range qb.modelSchema().Fields {
+		if field.PrimaryKey || field.AutoCreateTime > 0 || field.FieldType == deletedAtType || isTenantField(field) {
+			omit = append(omit, field.DBName)
+		}
+	}
+
+	res := qb.GetDB().Select("*").Omit(omit...).Updates(entity)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if version != nil && res.RowsAffected == 0 {
+		return nil, versionConflict[T](db, id)
+	}
+
+	return GetByID[T](db, id)
+}
+
+// DeleteByID 按主键删除（有 gorm.DeletedAt 字段时为软删除），不存在时返回 gorm.ErrRecordNotFound
+func DeleteByID[T any](db *gorm.DB, id interface{}) error {
+	return deleteByID[T](db, id, nil)
+}
+
+// deleteByID 按主键删除，version 不为 nil 时只删除该版本的记录，版本不一致返回 ErrVersionConflict
+func deleteByID[T any](db *gorm.DB, id, version interface{}) error {
+	if auditEnabled(db) {
+		return auditChanges[T](db, AuditDelete, idScope(id), func(tx *gorm.DB) error {
+			return deleteByID[T](tx, id, version)
+		})
+	}
+
+	var model T
+	qb := NewQueryBuilder(db.Model(&model))
+	if err := qb.whereID(id); err != nil {
+		return err
+	}
+	if version != nil {
+		if field := versionField(qb.modelSchema()); field != nil {
+			qb.db = qb.db.Where(qb.quote(field.DBName)+" = ?", version)
+		}
+	}
+
+	res := qb.GetDB().Delete(&model)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if version != nil {
+			return versionConflict[T](db, id)
+		}
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Create 创建记录，开启审计时写入审计日志
 func Create[T any](db *gorm.DB, entity *T) error {
+	initVersion(db, entity)
+	if auditEnabled(db) {
+		return auditCreate(db, func(tx *gorm.DB) error {
+			return tx.Create(entity).Error
+		}, func() []T {
+			return []T{*entity}
+		})
+	}
 	return db.Create(entity).Error
 }
 
+// resetGuarded 清空请求体中不允许客户端在创建时设置的字段：软删除字段与租户字段（限定租户时由回调填充）
+func resetGuarded[T any](db *gorm.DB, entity *T) error {
+	sch, err := parseSchema(db, entity)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(entity).Elem()
+	for _, field := range sch.Fields {
+		if field.FieldType == deletedAtType || isTenantField(field) {
+			field.ReflectValueOf(db.Statement.Context, rv).Set(reflect.Zero(field.FieldType))
+		}
+	}
+	return nil
+}
+
+// Update 按过滤条件更新。模型有版本字段时 updates 中必须带版本号，只更新该版本的记录；
+// 过滤条件命中的记录中有版本不一致的记录时整体回滚并返回 ErrVersionConflict
 func Update[T any](db *gorm.DB, filters interface{}, updates map[string]interface{}) (int64, error) {
 	if !HasAnyFilter(filters) {
 		return 0, ErrFilterRequired
 	}
 
-	var model T
-	qb := NewQueryBuilder(db)
-	qb.db = qb.db.Model(&model)
-	qb.ApplyFilters(filters)
+	if auditEnabled(db) {
+		var affected int64
+		err := auditChanges[T](db, AuditUpdate, filterScope(filters), func(tx *gorm.DB) (err error) {
+			affected, err = Update[T](tx, filters, updates)
+			return err
+		})
+		return affected, err
+	}
 
-	res := qb.GetDB().Updates(updates)
-	return res.RowsAffected, res.Error
+	var affected int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		qb := NewQueryBuilder(tx.Model(new(T)))
+		qb.ApplyFilters(filters)
+
+		columns, conditioned, err := qb.versionedUpdates(updates)
+		if err != nil {
+			return err
+		}
+
+		var matched int64
+		if conditioned {
+			if matched, err = matchedCount[T](tx, filters); err != nil {
+				return err
+			}
+		}
+
+		res := qb.GetDB().Updates(columns)
+		if res.Error != nil {
+			return res.Error
+		}
+		// 任一条命中的记录版本不一致时整体回滚，不做部分更新
+		if res.RowsAffected < matched {
+			return fmt.Errorf("%w: %d of %d matching records have been modified", ErrVersionConflict, matched-res.RowsAffected, matched)
+		}
+		affected = res.RowsAffected
+		return nil
+	})
+	return affected, err
+}
+
+// versionConflict 带版本条件的更新没有命中记录时，区分记录不存在与版本冲突
+func versionConflict[T any](db *gorm.DB, id interface{}) error {
+	if _, err := GetByID[T](db, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: record %v has been modified", ErrVersionConflict, id)
 }
 
+// Delete 按过滤条件删除，有 gorm.DeletedAt 字段的模型为软删除
 func Delete[T any](db *gorm.DB, filters interface{}) (int64, error) {
 	if !HasAnyFilter(filters) {
 		return 0, ErrFilterRequired
 	}
 
+	if auditEnabled(db) {
+		var affected int64
+		err := auditChanges[T](db, AuditDelete, filterScope(filters), func(tx *gorm.DB) (err error) {
+			affected, err = Delete[T](tx, filters)
+			return err
+		})
+		return affected, err
+	}
+
 	var model T
 	qb := NewQueryBuilder(db)
 	qb.db = qb.db.Model(&model)
 	qb.ApplyFilters(filters)
 
-	res := qb.GetDB().Unscoped().Delete(&model)
+	res := qb.GetDB().Delete(&model)
 	return res.RowsAffected, res.Error
 }
 
@@ -134,24 +481,76 @@ func HasAnyFilter(filters interface{}) bool {
 		return true
 	}
 
+	t := v.Type()
 	for i := 0; i < v.NumField(); i++ {
 		f := v.Field(i)
 		if !f.IsValid() {
 			continue
 		}
 
-		if f.Kind() == reflect.Ptr {
-			if !f.IsNil() {
+		// 嵌入结构体与逻辑分组需要至少包含一个实际条件
+		if t.Field(i).Anonymous && f.Kind() == reflect.Struct {
+			if HasAnyFilter(f.Interface()) {
 				return true
 			}
 			continue
 		}
 
-		zero := reflect.Zero(f.Type())
-		if !reflect.DeepEqual(f.Interface(), zero.Interface()) {
+		switch t.Field(i).Tag.Get("filter") {
+		case "and":
+			for j := 0; j < f.Len(); j++ {
+				if HasAnyFilter(f.Index(j).Interface()) {
+					return true
+				}
+			}
+			continue
+		case "or":
+			// 任一分组为空时整个 OR 恒真，不算有效过滤
+			if f.Len() > 0 && allHaveFilter(f) {
+				return true
+			}
+			continue
+		case "not":
+			if HasAnyFilter(f.Interface()) {
+				return true
+			}
+			continue
+		}
+
+		if !isEmptyFilterValue(f) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// isEmptyFilterValue 过滤字段的值是否不构成条件：nil、非指针字段的零值、空切片、空映射与空的 Range。
+// 指针指向的标量（包括零值，如 age >= 0）是有效条件
+func isEmptyFilterValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		switch v.Elem().Kind() {
+		case reflect.Slice, reflect.Map, reflect.Struct, reflect.Ptr, reflect.Interface:
+			return isEmptyFilterValue(v.Elem())
+		}
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func allHaveFilter(values reflect.Value) bool {
+	for i := 0; i < values.Len(); i++ {
+		if !HasAnyFilter(values.Index(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
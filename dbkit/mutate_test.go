@@ -0,0 +1,48 @@
+package dbkit
+
+import "testing"
+
+type hasFilterCase struct {
+	Age     *int                   `json:"age" filter:"gte"`
+	Name    string                 `json:"name" filter:"eq"`
+	IDs     []int                  `json:"ids" filter:"in"`
+	AgeOps  map[string]interface{} `json:"age_ops" column:"age"`
+	Range   *Range[int]            `json:"age_range" column:"age" filter:"between"`
+	Dynamic DynamicFilter          `json:"dynamic"`
+	Logic[hasFilterCase]
+}
+
+func TestHasAnyFilter(t *testing.T) {
+	zero, one := 0, 1
+
+	tests := []struct {
+		name    string
+		filters hasFilterCase
+		want    bool
+	}{
+		{name: "empty", want: false},
+		{name: "pointer to zero", filters: hasFilterCase{Age: &zero}, want: true},
+		{name: "scalar", filters: hasFilterCase{Name: "a"}, want: true},
+		{name: "empty in", filters: hasFilterCase{IDs: []int{}}, want: false},
+		{name: "in", filters: hasFilterCase{IDs: []int{1}}, want: true},
+		{name: "empty ops", filters: hasFilterCase{AgeOps: map[string]interface{}{}}, want: false},
+		{name: "empty range", filters: hasFilterCase{Range: &Range[int]{}}, want: false},
+		{name: "range", filters: hasFilterCase{Range: &Range[int]{Min: &one}}, want: true},
+		{name: "empty dynamic", filters: hasFilterCase{Dynamic: DynamicFilter{}}, want: false},
+		{name: "dynamic", filters: hasFilterCase{Dynamic: DynamicFilter{{Field: "age", Op: "eq", Value: 1}}}, want: true},
+		{name: "empty and", filters: hasFilterCase{Logic: Logic[hasFilterCase]{And: []hasFilterCase{{}}}}, want: false},
+		{name: "empty or", filters: hasFilterCase{Logic: Logic[hasFilterCase]{Or: []hasFilterCase{}}}, want: false},
+		{name: "or with empty branch", filters: hasFilterCase{Logic: Logic[hasFilterCase]{Or: []hasFilterCase{{Age: &one}, {}}}}, want: false},
+		{name: "or", filters: hasFilterCase{Logic: Logic[hasFilterCase]{Or: []hasFilterCase{{Age: &one}, {Name: "a"}}}}, want: true},
+		{name: "empty not", filters: hasFilterCase{Logic: Logic[hasFilterCase]{Not: &hasFilterCase{}}}, want: false},
+		{name: "not", filters: hasFilterCase{Logic: Logic[hasFilterCase]{Not: &hasFilterCase{Age: &one}}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasAnyFilter(tt.filters); got != tt.want {
+				t.Fatalf("HasAnyFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
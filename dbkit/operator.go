@@ -0,0 +1,128 @@
+package dbkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// likeEscaper 转义 LIKE 中用户输入的通配符，配合 ESCAPE '!' 使用（各数据库写法一致）
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func escapeLike(value interface{}) string {
+	return likeEscaper.Replace(fmt.Sprint(value))
+}
+
+// where 追加条件，sql 为空表示当前数据库不支持，错误已记录
+func (qb *QueryBuilder) where(sql string, vars ...interface{}) {
+	if sql == "" {
+		return
+	}
+	qb.db = qb.db.Where(sql, vars...)
+}
+
+func (qb *QueryBuilder) unsupported(operator string) {
+	qb.db.AddError(fmt.Errorf("%w: operator %s is not supported by %s", ErrInvalidFilter, operator, qb.db.Dialector.Name()))
+}
+
+// dialectILike 不区分大小写的包含匹配
+func (qb *QueryBuilder) dialectILike(column string) string {
+	if qb.db.Dialector.Name() == "postgres" {
+		return fmt.Sprintf("%s ILIKE ? ESCAPE '!'", column)
+	}
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(?) ESCAPE '!'", column)
+}
+
+// dialectRegex 正则匹配（SQLite 需要注册 REGEXP 函数）
+func (qb *QueryBuilder) dialectRegex(column string, value interface{}) (string, interface{}) {
+	switch qb.db.Dialector.Name() {
+	case "mysql", "sqlite":
+		return fmt.Sprintf("%s REGEXP ?", column), value
+	case "postgres":
+		return fmt.Sprintf("%s ~ ?", column), value
+	}
+	qb.unsupported("regex")
+	return "", nil
+}
+
+// dialectMatch 全文检索（MySQL 需要 FULLTEXT 索引）
+func (qb *QueryBuilder) dialectMatch(column string, value interface{}) (string, interface{}) {
+	switch qb.db.Dialector.Name() {
+	case "mysql":
+		return fmt.Sprintf("MATCH(%s) AGAINST (? IN NATURAL LANGUAGE MODE)", column), value
+	case "postgres":
+		return fmt.Sprintf("to_tsvector(%s) @@ plainto_tsquery(?)", column), value
+	}
+	qb.unsupported("match")
+	return "", nil
+}
+
+// dialectJSONContains JSON 列（或其中 path 指向的部分）包含给定值
+func (qb *QueryBuilder) dialectJSONContains(column, path string, value interface{}) (string, []interface{}) {
+	doc, err := json.Marshal(value)
+	if err != nil {
+		qb.db.AddError(fmt.Errorf("%w: value of %s is not valid JSON: %v", ErrInvalidFilter, column, err))
+		return "", nil
+	}
+
+	switch qb.db.Dialector.Name() {
+	case "mysql":
+		if path != "" {
+			return fmt.Sprintf("JSON_CONTAINS(%s, ?, ?)", column), []interface{}{string(doc), path}
+		}
+		return fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), []interface{}{string(doc)}
+	case "postgres":
+		if path != "" {
+			return fmt.Sprintf("%s #> ? @> ?::jsonb", column), []interface{}{pgJSONPath(path), string(doc)}
+		}
+		return fmt.Sprintf("%s @> ?::jsonb", column), []interface{}{string(doc)}
+	case "sqlite":
+		// json_each 逐个比较元素，只能匹配标量
+		switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+			qb.db.AddError(fmt.Errorf("%w: json_contains on %s only supports scalar values on sqlite", ErrInvalidFilter, column))
+			return "", nil
+		}
+		if path == "" {
+			path = "$"
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s, ?) WHERE value = ?)", column), []interface{}{path, value}
+	}
+	qb.unsupported("json_contains")
+	return "", nil
+}
+
+// dialectOverlap 数组（JSON 数组）与给定列表存在交集；PostgreSQL 按元素展开为 ARRAY[...] 字面量
+func (qb *QueryBuilder) dialectOverlap(column string, value interface{}) (string, []interface{}) {
+	switch qb.db.Dialector.Name() {
+	case "mysql":
+		doc, err := json.Marshal(value)
+		if err != nil {
+			qb.db.AddError(fmt.Errorf("%w: value of %s is not valid JSON: %v", ErrInvalidFilter, column, err))
+			return "", nil
+		}
+		return fmt.Sprintf("JSON_OVERLAPS(%s, ?)", column), []interface{}{string(doc)}
+	case "postgres":
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array || v.Len() == 0 {
+			qb.db.AddError(fmt.Errorf("%w: overlap on %s requires a non-empty list", ErrInvalidFilter, column))
+			return "", nil
+		}
+		vars := make([]interface{}, v.Len())
+		for i := range vars {
+			vars[i] = v.Index(i).Interface()
+		}
+		return fmt.Sprintf("%s && ARRAY[%s]", column, strings.TrimSuffix(strings.Repeat("?,", len(vars)), ",")), vars
+	case "sqlite":
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE value IN ?)", column), []interface{}{value}
+	}
+	qb.unsupported("overlap")
+	return "", nil
+}
+
+// pgJSONPath 将 $.a.b 形式的路径转换为 PostgreSQL 的 {a,b}
+func pgJSONPath(path string) string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	return "{" + strings.ReplaceAll(path, ".", ",") + "}"
+}
@@ -0,0 +1,130 @@
+package dbkit
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// namedDialector 以 MySQL 生成 SQL，但报告为其他数据库，用于测试方言分支
+type namedDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (d namedDialector) Name() string { return d.name }
+
+func dialectDB(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(namedDialector{Dialector: mysql.New(mysql.Config{
+		DSN:                       "user:pass@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), name: name}, testConfig)
+	if err != nil {
+		t.Fatalf("open %s db: %v", name, err)
+	}
+	return db.Session(&gorm.Session{DryRun: true})
+}
+
+func TestClientErrorsAreBadRequests(t *testing.T) {
+	empty, upper := "", "ASC"
+
+	tests := []struct {
+		name  string
+		db    func(t *testing.T) *gorm.DB
+		apply func(qb *QueryBuilder) *QueryBuilder
+	}{
+		{name: "unsupported operator", db: func(t *testing.T) *gorm.DB { return dialectDB(t, "sqlite") }, apply: func(qb *QueryBuilder) *QueryBuilder {
+			qb.applyFilter("`name`", "match", "go")
+			return qb
+		}},
+		{name: "json_contains value", db: dryRunDB, apply: func(qb *QueryBuilder) *QueryBuilder {
+			qb.applyFilter("`name`", "json_contains", math.Inf(1))
+			return qb
+		}},
+		{name: "overlap value", db: dryRunDB, apply: func(qb *QueryBuilder) *QueryBuilder {
+			qb.applyFilter("`name`", "overlap", []float64{math.NaN()})
+			return qb
+		}},
+		{name: "empty order", db: dryRunDB, apply: func(qb *QueryBuilder) *QueryBuilder {
+			return qb.ApplyOrders(&struct {
+				Age *string `json:"age"`
+			}{Age: &empty})
+		}},
+		{name: "order not a string", db: dryRunDB, apply: func(qb *QueryBuilder) *QueryBuilder {
+			n := 1
+			return qb.ApplyOrders(&struct {
+				Age *int `json:"age"`
+			}{Age: &n})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := tt.apply(NewQueryBuilder(tt.db(t).Model(&testUser{})))
+			err := qb.GetDB().Find(&[]testUser{}).Error
+			if err == nil {
+				t.Fatal("want an error")
+			}
+			if status := TranslateError(err).Status; status != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %v", status, err)
+			}
+		})
+	}
+
+	// 方向不区分大小写
+	qb := NewQueryBuilder(dryRunDB(t).Model(&testUser{})).ApplyOrders(&struct {
+		Age *string `json:"age"`
+	}{Age: &upper})
+	if err := qb.GetDB().Find(&[]testUser{}).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDialectOperators(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		op      string
+		value   interface{}
+		want    string
+	}{
+		{name: "mysql overlap", dialect: "mysql", op: "overlap", value: []string{"a", "b"}, want: `JSON_OVERLAPS(` + "`tags`" + `, '["a","b"]')`},
+		{name: "postgres overlap", dialect: "postgres", op: "overlap", value: []string{"a", "b"}, want: "`tags` && ARRAY['a','b']"},
+		{name: "postgres overlap empty", dialect: "postgres", op: "overlap", value: []string{}},
+		{name: "postgres overlap scalar", dialect: "postgres", op: "overlap", value: "a"},
+		{name: "sqlite overlap", dialect: "sqlite", op: "overlap", value: []string{"a", "b"}, want: "EXISTS (SELECT 1 FROM json_each(`tags`) WHERE value IN ('a','b'))"},
+		{name: "sqlite json_contains", dialect: "sqlite", op: "json_contains", value: "a", want: "EXISTS (SELECT 1 FROM json_each(`tags`, '$') WHERE value = 'a')"},
+		{name: "sqlite json_contains list", dialect: "sqlite", op: "json_contains", value: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			sql := toSQL(t, dialectDB(t, tt.dialect), func(tx *gorm.DB) *gorm.DB {
+				qb := NewQueryBuilder(tx.Model(&testUser{}))
+				qb.applyFilter("`tags`", tt.op, tt.value)
+				res := qb.GetDB().Find(&[]testUser{})
+				err = res.Error
+				return res
+			})
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidFilter) {
+					t.Fatalf("err = %v, want ErrInvalidFilter", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(sql, tt.want) {
+				t.Fatalf("sql %q does not contain %q", sql, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package dbkit
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Option 通用处理器与查询函数的可选配置
+type Option func(*options)
+
+type options struct {
+	totalMode   TotalMode
+	maxPageSize int
+	writer      ResponseWriter
+	hooks       []interface{} // Hooks[T]，按处理器的实体类型取出
+	tenant      TenantResolver
+	policy      interface{} // Policy[T]
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		totalMode:   TotalExact,
+		maxPageSize: defaultMaxPageSize,
+		writer:      EnvelopeWriter{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// handlerOptions 创建处理器时解析选项，开启租户隔离时在此注册 GORM 回调，避免在请求中注册与查询并发
+func handlerOptions(db *gorm.DB, opts []Option) *options {
+	o := newOptions(opts)
+	if o.tenant != nil {
+		registerTenantCallbacks(db)
+	}
+	return o
+}
+
+// WithTotalMode 设置默认的总数统计方式（请求中 with_total=false 时始终不统计）
+func WithTotalMode(mode TotalMode) Option {
+	return func(o *options) {
+		o.totalMode = mode
+	}
+}
+
+// WithMaxPageSize 设置每页条数上限，超出时返回校验错误，n <= 0 表示不限制
+func WithMaxPageSize(n int) Option {
+	return func(o *options) {
+		o.maxPageSize = n
+	}
+}
+
+// respondError 按配置的 ResponseWriter 响应错误
+func (o *options) respondError(c *gin.Context, err error) {
+	writeError(c, o.writer, err)
+}
@@ -1,33 +1,38 @@
 package dbkit
 
 import (
+	"reflect"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Logic 嵌套逻辑条件，嵌入到过滤结构体中即可支持任意层级的 and/or/not 分组：
+//
+//	type UserFilters struct {
+//	    Age *int `json:"age" filter:"gte"`
+//	    dbkit.Logic[UserFilters]
+//	}
+//
+// 请求示例：{"age": 18, "or": [{"name": "admin"}, {"not": {"age": 30}}]}
+type Logic[F any] struct {
+	And []F `json:"and,omitempty" filter:"and"` // 每个元素都需满足
+	Or  []F `json:"or,omitempty" filter:"or"`   // 任一元素满足即可
+	Not *F  `json:"not,omitempty" filter:"not"` // 取反
+}
+
 // OrCondition 表示 OR 条件组
 type OrCondition struct {
 	Conditions []interface{} `json:"conditions"`
 }
 
-// ApplyOrConditions 应用 OR 条件
+// ApplyOrConditions 应用 OR 条件，多个条件作为一个整体括号与其他条件 AND
 func (qb *QueryBuilder) ApplyOrConditions(orConditions []interface{}) *QueryBuilder {
-	if len(orConditions) == 0 {
-		return qb
-	}
-
-	// 构建 OR 条件
+	values := make([]reflect.Value, len(orConditions))
 	for i, condition := range orConditions {
-		tempDB := qb.db.Session(&gorm.Session{NewDB: true})
-		tempQB := &QueryBuilder{db: tempDB}
-		tempQB.ApplyFilters(condition)
-
-		if i == 0 {
-			qb.db = qb.db.Where(tempQB.db)
-		} else {
-			qb.db = qb.db.Or(tempQB.db)
-		}
+		values[i] = reflect.ValueOf(condition)
 	}
-
+	qb.applyOr(values)
 	return qb
 }
 
@@ -55,3 +60,82 @@ func (qb *QueryBuilder) ApplyExtendedFilters(filters *ExtendedFilters) *QueryBui
 
 	return qb
 }
+
+// applyLogic 处理 filter:"and" / "or" / "not" 标记的字段
+func (qb *QueryBuilder) applyLogic(operator string, value reflect.Value) {
+	switch operator {
+	case "and":
+		for i := 0; i < value.Len(); i++ {
+			if sub := qb.subConditions(value.Index(i)); sub != nil {
+				qb.db = qb.db.Where(sub)
+			}
+		}
+	case "or":
+		values := make([]reflect.Value, value.Len())
+		for i := range values {
+			values[i] = value.Index(i)
+		}
+		qb.applyOr(values)
+	case "not":
+		if sub := qb.subConditions(value); sub != nil {
+			qb.db = qb.db.Not(sub)
+		}
+	}
+}
+
+// applyOr 将多个条件组以 OR 连接后整体加括号，任一分组为空时等价于恒真，整体忽略
+func (qb *QueryBuilder) applyOr(values []reflect.Value) {
+	var group *gorm.DB
+	for _, v := range values {
+		sub := qb.subConditions(v)
+		if sub == nil {
+			return
+		}
+
+		if group == nil {
+			group = qb.newSession().Where(sub)
+		} else {
+			group = group.Or(sub)
+		}
+	}
+
+	if group != nil {
+		qb.db = qb.db.Where(group)
+	}
+}
+
+// subConditions 在独立会话中构建子条件组，没有任何条件时返回 nil
+func (qb *QueryBuilder) subConditions(v reflect.Value) *gorm.DB {
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	sub := &QueryBuilder{db: qb.newSession(), sch: qb.modelSchema(), hidden: qb.hidden}
+	sub.ApplyFilters(v.Interface())
+
+	if sub.db.Error != nil {
+		qb.db.AddError(sub.db.Error)
+		return nil
+	}
+
+	if !hasConditions(sub.db) {
+		return nil
+	}
+	return sub.db
+}
+
+func (qb *QueryBuilder) newSession() *gorm.DB {
+	return qb.db.Session(&gorm.Session{NewDB: true})
+}
+
+// hasConditions 判断是否已有 WHERE 条件
+func hasConditions(db *gorm.DB) bool {
+	c, ok := db.Statement.Clauses["WHERE"]
+	if !ok {
+		return false
+	}
+	where, ok := c.Expression.(clause.Where)
+	return ok && len(where.Exprs) > 0
+}
@@ -1,8 +1,14 @@
 package dbkit
 
+import "errors"
+
+var ErrInvalidPage = errors.New("invalid page")
+
 type Page struct {
-	PageNum  int `json:"page_num"`
-	PageSize int `json:"page_size"`
+	PageNum   int     `json:"page_num"`
+	PageSize  int     `json:"page_size"`
+	Cursor    *string `json:"cursor,omitempty"`     // 游标分页：首页传空串，翻页传上次返回的 next_cursor/prev_cursor
+	WithTotal *bool   `json:"with_total,omitempty"` // 是否统计总数，false 时不执行 COUNT，改为返回 has_more
 }
 
 type Pageable interface {
@@ -11,8 +17,14 @@ type Pageable interface {
 	GetLimit() int
 }
 
+// IsValid 是否为有效的页码分页（游标分页不走 OFFSET）
 func (p *Page) IsValid() bool {
-	return p != nil && p.PageNum > 0 && p.PageSize > 0
+	return p != nil && p.Cursor == nil && p.PageNum > 0 && p.PageSize > 0
+}
+
+// IsCursor 是否为游标分页
+func (p *Page) IsCursor() bool {
+	return p != nil && p.Cursor != nil && p.PageSize > 0
 }
 
 func (p *Page) GetOffset() int {
@@ -0,0 +1,361 @@
+package dbkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Policy 资源的授权策略，通用处理器在每个请求中评估。返回错误时拒绝请求（通常为 Abort(http.StatusForbidden, ...)），
+// 返回的 Permission 为 nil 时不加限制。请求中的身份由认证中间件放入 gin.Context，如 c.MustGet("user")。
+//
+//	CanQuery  查询、统计、GET /:id、变更历史
+//	CanCreate 创建、批量创建（逐条）
+//	CanUpdate 更新、PATCH /:id、批量更新（逐条）；PUT /:id 时 updates 为 nil
+//	CanDelete 删除、批量删除、恢复与永久删除
+type Policy[T any] interface {
+	CanQuery(c *gin.Context) (*Permission, error)
+	CanCreate(c *gin.Context, entity *T) (*Permission, error)
+	CanUpdate(c *gin.Context, filters interface{}, updates map[string]interface{}) (*Permission, error)
+	CanDelete(c *gin.Context, filters interface{}) (*Permission, error)
+}
+
+// Permission 策略允许操作时附加的限制，字段名可为 json 名或列名
+type Permission struct {
+	Scope    DynamicFilter // 强制追加的过滤条件，作用于该请求的所有读写，如只能操作本部门的记录
+	Hidden   []string      // 响应中隐藏的字段
+	ReadOnly []string      // 禁止修改的字段：更新中包含时返回 403，PUT /:id 时保留原值
+}
+
+// AllowAll 允许所有操作的策略，可嵌入自定义策略中只实现需要限制的方法
+type AllowAll[T any] struct{}
+
+func (AllowAll[T]) CanQuery(c *gin.Context) (*Permission, error) {
+	return nil, nil
+}
+
+func (AllowAll[T]) CanCreate(c *gin.Context, entity *T) (*Permission, error) {
+	return nil, nil
+}
+
+func (AllowAll[T]) CanUpdate(c *gin.Context, filters interface{}, updates map[string]interface{}) (*Permission, error) {
+	return nil, nil
+}
+
+func (AllowAll[T]) CanDelete(c *gin.Context, filters interface{}) (*Permission, error) {
+	return nil, nil
+}
+
+// WithPolicy 为通用处理器设置授权策略
+func WithPolicy[T any](policy Policy[T]) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// policyOf 取出实体类型 T 的策略，未设置时允许所有操作，类型不匹配时 panic
+func policyOf[T any](o *options) Policy[T] {
+	if o.policy == nil {
+		return AllowAll[T]{}
+	}
+	policy, ok := o.policy.(Policy[T])
+	if !ok {
+		var model T
+		panic(fmt.Sprintf("dbkit: policy of type %T cannot be used with %T", o.policy, model))
+	}
+	return policy
+}
+
+// hiddenSettingKey gorm.DB 上策略隐藏的列
+const hiddenSettingKey = "dbkit:hidden"
+
+// hiddenColumns 策略对模型 model 隐藏的列（数据库列名），请求中引用时返回 403，避免通过过滤、排序或统计推断出隐藏的值
+type hiddenColumns struct {
+	model   reflect.Type
+	columns map[string]bool
+}
+
+// checkHidden 字段被策略隐藏时返回 403，只作用于隐藏字段所属的模型（关联表的同名列不受影响）
+func (qb *QueryBuilder) checkHidden(field *schema.Field) error {
+	h := qb.hidden
+	if h == nil || !h.columns[field.DBName] || field.Schema == nil || field.Schema.ModelType != h.model {
+		return nil
+	}
+	name := jsonName(field)
+	return &APIError{
+		Status:  http.StatusForbidden,
+		Code:    CodeForbidden,
+		Message: "accessing these fields is not allowed",
+		Errors:  []FieldError{{Field: name, Rule: "hidden", Message: name + " is not accessible"}},
+	}
+}
+
+// policyScopeSettingKey 标记 gorm.DB 带有策略的强制过滤条件（在执行时才追加，语句上看不到）
+const policyScopeSettingKey = "dbkit:policy_scope"
+
+// restrict 将权限中的强制过滤条件作用于 db 之后的所有 T 的语句（包括按主键读写与审计快照），
+// 并禁止在请求中引用隐藏的字段
+func restrict[T any](db *gorm.DB, perms ...*Permission) *gorm.DB {
+	var scope DynamicFilter
+	var hidden []string
+	for _, perm := range perms {
+		if perm != nil {
+			scope = append(scope, perm.Scope...)
+			hidden = append(hidden, perm.Hidden...)
+		}
+	}
+
+	modelType := reflect.TypeOf((*T)(nil)).Elem()
+	if len(hidden) > 0 {
+		if sch, err := parseSchema(db, new(T)); err == nil {
+			h := &hiddenColumns{model: modelType, columns: make(map[string]bool, len(hidden))}
+			for _, name := range hidden {
+				if field := schemaField(sch, name); field != nil {
+					h.columns[field.DBName] = true
+				}
+			}
+			db = db.Set(hiddenSettingKey, h)
+		}
+	}
+	if len(scope) == 0 {
+		return db
+	}
+
+	return db.Set(policyScopeSettingKey, true).Scopes(func(tx *gorm.DB) *gorm.DB {
+		if !isModelOf(tx.Statement.Model, modelType) {
+			return tx
+		}
+		// 强制过滤条件由服务端给出，可以引用隐藏的列
+		qb := NewQueryBuilder(tx)
+		qb.hidden = nil
+		return qb.ApplyDynamicFilter(scope).GetDB()
+	}).Session(&gorm.Session{})
+}
+
+// isModelOf 语句的 Model（*T、*[]T 等）是否为 t 类型
+func isModelOf(model interface{}, t reflect.Type) bool {
+	mt := reflect.TypeOf(model)
+	for mt != nil && (mt.Kind() == reflect.Ptr || mt.Kind() == reflect.Slice || mt.Kind() == reflect.Array) {
+		mt = mt.Elem()
+	}
+	return mt == t
+}
+
+// createInScope 执行 create；perms[i] 带强制过滤条件时，在同一事务中确认 entities[i] 创建后仍在该范围内，
+// 否则回滚并返回 403，避免创建出自己无法读取的记录
+func createInScope[T any](db *gorm.DB, entities []*T, perms []*Permission, create func(tx *gorm.DB) error) error {
+	scoped := false
+	for _, perm := range perms {
+		scoped = scoped || (perm != nil && len(perm.Scope) > 0)
+	}
+	if !scoped {
+		return create(db)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := create(tx); err != nil {
+			return err
+		}
+		for i, entity := range entities {
+			if perms[i] == nil || len(perms[i].Scope) == 0 {
+				continue
+			}
+			id, err := primaryKeys(tx, []T{*entity})
+			if err != nil {
+				return err
+			}
+			if _, err := GetByID[T](restrict[T](tx, perms[i]), id[0]); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return Abort(http.StatusForbidden, "creating records outside the permitted scope is not allowed")
+				}
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// checkReadOnly 更新字段中包含只读字段时返回 403
+func checkReadOnly[T any](db *gorm.DB, perm *Permission, updates map[string]interface{}) error {
+	if perm == nil || len(perm.ReadOnly) == 0 {
+		return nil
+	}
+	sch, err := parseSchema(db, new(T))
+	if err != nil {
+		return err
+	}
+
+	readonly := make(map[string]bool, len(perm.ReadOnly))
+	for _, name := range perm.ReadOnly {
+		if field := schemaField(sch, name); field != nil {
+			readonly[field.DBName] = true
+		}
+	}
+
+	var fieldErrors []FieldError
+	for name := range updates {
+		if field := schemaField(sch, name); field != nil && readonly[field.DBName] {
+			fieldErrors = append(fieldErrors, FieldError{Field: name, Rule: "readonly", Message: name + " cannot be updated"})
+		}
+	}
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	return &APIError{
+		Status:  http.StatusForbidden,
+		Code:    CodeForbidden,
+		Message: "updating these fields is not allowed",
+		Errors:  fieldErrors,
+	}
+}
+
+// keepReadOnly PUT /:id 时将只读字段恢复为当前记录中的值
+func keepReadOnly[T any](db *gorm.DB, perm *Permission, id interface{}, entity *T) error {
+	if perm == nil || len(perm.ReadOnly) == 0 {
+		return nil
+	}
+	current, err := GetByID[T](db, id)
+	if err != nil {
+		return err
+	}
+	sch, err := parseSchema(db, entity)
+	if err != nil {
+		return err
+	}
+
+	dst, src := reflect.ValueOf(entity).Elem(), reflect.ValueOf(current).Elem()
+	for _, name := range perm.ReadOnly {
+		field := schemaField(sch, name)
+		if field == nil {
+			continue
+		}
+		value, _ := field.ValueOf(db.Statement.Context, src)
+		if err := field.Set(db.Statement.Context, dst, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hiddenNames 隐藏字段在响应中的 json 名
+func hiddenNames[T any](db *gorm.DB, perm *Permission) map[string]bool {
+	if perm == nil || len(perm.Hidden) == 0 {
+		return nil
+	}
+	names := make(map[string]bool, len(perm.Hidden))
+	sch, _ := parseSchema(db, new(T))
+	for _, name := range perm.Hidden {
+		if sch != nil {
+			if field := schemaField(sch, name); field != nil {
+				name = jsonName(field)
+			}
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// fieldMask 响应中字段的取舍（均为 json 名）：only 不为空时只输出其中的字段，hidden 中的字段总是隐藏
+type fieldMask struct {
+	only   map[string]bool
+	hidden map[string]bool
+}
+
+func (m fieldMask) empty() bool {
+	return len(m.only) == 0 && len(m.hidden) == 0
+}
+
+func (m fieldMask) keep(key string) bool {
+	return !m.hidden[key] && (len(m.only) == 0 || m.only[key])
+}
+
+// apply 按 m 输出记录，没有需要取舍的字段时原样返回
+func (m fieldMask) apply(record interface{}) interface{} {
+	if m.empty() {
+		return record
+	}
+	return maskedRecord{record: record, mask: m}
+}
+
+// maskedRecord 取舍字段后输出的记录，保留的字段保持原顺序
+type maskedRecord struct {
+	record interface{}
+	mask   fieldMask
+}
+
+// mask 隐藏记录中的字段，没有需要隐藏的字段时原样返回
+func mask(record interface{}, hidden map[string]bool) interface{} {
+	return fieldMask{hidden: hidden}.apply(record)
+}
+
+func (m maskedRecord) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(m.record)
+	if err != nil {
+		return nil, err
+	}
+	return maskJSON(raw, m.mask)
+}
+
+// maskJSON 按 m 删除 JSON 对象中的字段，非对象原样返回
+func maskJSON(raw []byte, m fieldMask) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return raw, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		if !m.keep(key) {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// maskAuditLogs 隐藏审计日志快照中的字段
+func maskAuditLogs(logs []AuditLog, hidden map[string]bool) error {
+	if len(hidden) == 0 {
+		return nil
+	}
+	for i := range logs {
+		for _, snapshot := range []*json.RawMessage{&logs[i].Before, &logs[i].After} {
+			if len(*snapshot) == 0 {
+				continue
+			}
+			masked, err := maskJSON(*snapshot, fieldMask{hidden: hidden})
+			if err != nil {
+				return err
+			}
+			*snapshot = masked
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package dbkit
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testPolicy 查询与创建都返回 perm
+type testPolicy struct {
+	AllowAll[testUser]
+	perm *Permission
+}
+
+func (p testPolicy) CanQuery(c *gin.Context) (*Permission, error) {
+	return p.perm, nil
+}
+
+func (p testPolicy) CanCreate(c *gin.Context, entity *testUser) (*Permission, error) {
+	return p.perm, nil
+}
+
+type policyFilters struct {
+	Age      *int          `json:"age" filter:"gte"`
+	TenantID *uint         `json:"tenant_id" filter:"eq"`
+	Where    DynamicFilter `json:"where"`
+}
+
+type policyOrders struct {
+	Age      *string `json:"age"`
+	TenantID *string `json:"tenant_id"`
+}
+
+func TestHiddenColumnsAreRejected(t *testing.T) {
+	policy := WithPolicy[testUser](testPolicy{perm: &Permission{
+		Scope:  DynamicFilter{{Field: "tenant_id", Op: "eq", Value: 1}},
+		Hidden: []string{"tenant_id"},
+	}})
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "filter", path: "/query", body: `{"filters":{"tenant_id":2}}`},
+		{name: "or filter", path: "/query", body: `{"filters":{"where":[{"field":"tenant_id","op":"eq","value":2}]}}`},
+		{name: "where", path: "/query", body: `{"where":[{"field":"tenant_id","op":"eq","value":2}]}`},
+		{name: "order", path: "/query", body: `{"orders":{"tenant_id":"asc"}}`},
+		{name: "fields", path: "/query", body: `{"fields":["name","tenant_id"]}`},
+		{name: "sum", path: "/stats", body: `{"stats_config":{"sum_fields":["tenant_id"]}}`},
+		{name: "max", path: "/stats", body: `{"stats_config":{"max_fields":["tenant_id"]}}`},
+		{name: "percentile", path: "/stats", body: `{"stats_config":{"percentile_fields":["tenant_id"]}}`},
+		{name: "histogram", path: "/stats", body: `{"stats_config":{"histograms":[{"field":"tenant_id","interval":1}]}}`},
+		{name: "stats group by", path: "/stats", body: `{"stats_config":{"group_by":["tenant_id"]}}`},
+		{name: "group by", path: "/group", body: `{"group_by":["tenant_id"]}`},
+		{name: "group aggregate", path: "/group", body: `{"group_by":["age"],"aggregates":[{"func":"max","column":"tenant_id","alias":"m"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+			var handler gin.HandlerFunc
+			switch tt.path {
+			case "/query":
+				handler = GenericQueryHandler[testUser, policyFilters, policyOrders](db, policy)
+			case "/stats":
+				handler = GenericStatsHandler[testUser, policyFilters, policyOrders](db, policy)
+			case "/group":
+				handler = GenericGroupHandler[testUser, policyFilters](db, policy)
+			}
+
+			w := serve(tt.path, http.MethodPost, tt.path, tt.body, handler, nil)
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+			}
+			// 只有强制过滤条件引用隐藏字段
+			for _, sql := range rec.SQL() {
+				if strings.Contains(strings.ReplaceAll(sql, "`tenant_id` = ?", ""), "tenant_id") {
+					t.Fatalf("hidden column was queried: %s", sql)
+				}
+			}
+		})
+	}
+}
+
+func TestHiddenColumnsInScope(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	handler := GenericStatsHandler[testUser, policyFilters, policyOrders](db, WithPolicy[testUser](testPolicy{perm: &Permission{
+		Scope:  DynamicFilter{{Field: "tenant_id", Op: "eq", Value: 1}},
+		Hidden: []string{"tenant_id"},
+	}}))
+
+	// 强制过滤条件可以引用隐藏字段
+	w := serve("/stats", http.MethodPost, "/stats", `{"filters":{"age":18},"stats_config":{"sum_fields":["age"]}}`, handler, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if sql := rec.last("SUM("); !strings.Contains(sql, "`age` >= ? AND `tenant_id` = ? | 18, 1") {
+		t.Fatalf("stats are not scoped: %s", sql)
+	}
+}
+
+func TestCreateOutsideScope(t *testing.T) {
+	policy := WithPolicy[testUser](testPolicy{perm: &Permission{
+		Scope: DynamicFilter{{Field: "age", Op: "gte", Value: 18}},
+	}})
+
+	// 创建后按范围查不到记录：回滚并返回 403
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	w := serve("/", http.MethodPost, "/", `{"id":1,"name":"kid","age":10}`, GenericCreateHandler[testUser](db, policy), nil)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+	}
+	if sql := rec.last("SELECT"); !strings.Contains(sql, "`id` = ? AND `age` >= ?") {
+		t.Fatalf("created record is not checked against the scope: %s", sql)
+	}
+	if rec.last("ROLLBACK") == "" {
+		t.Fatalf("create was not rolled back: %v", rec.SQL())
+	}
+
+	db, rec = recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.result = existingUser
+	w = serve("/", http.MethodPost, "/", `[{"id":1,"name":"adult","age":20}]`, GenericBatchCreateHandler[testUser](db, 10, policy), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if rec.last("ROLLBACK") != "" {
+		t.Fatalf("create was rolled back: %v", rec.SQL())
+	}
+}
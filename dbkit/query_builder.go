@@ -3,17 +3,34 @@ package dbkit
 import (
 	"fmt"
 	"reflect"
+	"slices"
+	"sort"
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 type QueryBuilder struct {
-	db *gorm.DB
+	db     *gorm.DB
+	sch    *schema.Schema // 模型 schema，子条件组会话中 Model 会丢失，需要沿用
+	hidden *hiddenColumns // 策略隐藏的列，不允许在过滤、排序、统计与返回字段中引用
 }
 
 func NewQueryBuilder(db *gorm.DB) *QueryBuilder {
-	return &QueryBuilder{db: db}
+	qb := &QueryBuilder{db: db}
+	if v, ok := db.Get(hiddenSettingKey); ok {
+		qb.hidden, _ = v.(*hiddenColumns)
+	}
+	return qb
+}
+
+// modelSchema 返回当前 Model 的 schema，未设置 Model 时返回 nil
+func (qb *QueryBuilder) modelSchema() *schema.Schema {
+	if qb.sch == nil && qb.db.Statement.Model != nil {
+		qb.sch, _ = parseSchema(qb.db, qb.db.Statement.Model)
+	}
+	return qb.sch
 }
 
 func (qb *QueryBuilder) ApplyFilters(filters interface{}) *QueryBuilder {
@@ -40,28 +57,106 @@ func (qb *QueryBuilder) ApplyFilters(filters interface{}) *QueryBuilder {
 			continue
 		}
 
-		jsonTag := fieldType.Tag.Get("json")
-		if jsonTag == "" || jsonTag == "-" {
+		// 嵌入的结构体（如 Logic[F]）展开处理
+		if fieldType.Anonymous && field.Kind() == reflect.Struct {
+			qb.ApplyFilters(field.Interface())
+			continue
+		}
+
+		if df, ok := field.Interface().(DynamicFilter); ok {
+			qb.ApplyDynamicFilter(df)
 			continue
 		}
 
-		columnName := strings.Split(jsonTag, ",")[0]
 		filterTag := fieldType.Tag.Get("filter")
+		if isLogicOperator(filterTag) {
+			qb.applyLogic(filterTag, field)
+			continue
+		}
 
-		var value interface{}
+		columnName := fieldColumn(fieldType)
+		if columnName == "" {
+			continue
+		}
+
+		var value reflect.Value
 		if field.Kind() == reflect.Ptr {
-			value = field.Elem().Interface()
+			value = field.Elem()
 		} else {
-			value = field.Interface()
+			value = field
+		}
+
+		apply := func(qb *QueryBuilder, column string) {
+			// 操作符映射：{"age": {"gte": 18, "lt": 65}}
+			if value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String {
+				ops := operatorMapOps(filterTag, qb.modelSchema(), columnName[strings.LastIndex(columnName, ".")+1:])
+				keys := value.MapKeys()
+				sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+				for _, key := range keys {
+					if !slices.Contains(ops, key.String()) {
+						qb.db.AddError(fmt.Errorf("%w: operator %q is not allowed on %q", ErrInvalidFilter, key.String(), fieldType.Name))
+						return
+					}
+					qb.applyFilter(column, key.String(), value.MapIndex(key).Interface())
+				}
+				return
+			}
+			qb.applyFilter(column, filterTag, value.Interface())
+		}
+
+		// 关联字段：column:"department.name"
+		if strings.Contains(columnName, ".") {
+			qb.applyRelationFilter(columnName, apply)
+			continue
 		}
 
-		qb.applyFilter(columnName, filterTag, value)
+		column, err := qb.quoteColumn(columnName)
+		if err != nil {
+			qb.db.AddError(err)
+			continue
+		}
+		apply(qb, column)
 	}
 
 	return qb
 }
 
+// fieldColumn 字段对应的列名：优先 column 标签（可为 "关联.列名"），其次 json 标签
+func fieldColumn(field reflect.StructField) string {
+	if column := field.Tag.Get("column"); column != "" {
+		return column
+	}
+
+	jsonTag := field.Tag.Get("json")
+	if jsonTag == "" || jsonTag == "-" {
+		return ""
+	}
+	return strings.Split(jsonTag, ",")[0]
+}
+
+// operatorMapOps 操作符映射字段允许的操作符：字段的 filter 标签（逗号分隔）优先，
+// 否则沿用实体列的 ops 标签或按类型推断，与动态过滤条件一致
+func operatorMapOps(tag string, sch *schema.Schema, name string) []string {
+	if tag != "" {
+		return strings.Split(tag, ",")
+	}
+	if sch == nil {
+		return nil
+	}
+	if field := lookupField(sch, name); field != nil {
+		return fieldOps(field)
+	}
+	return nil
+}
+
+func isLogicOperator(operator string) bool {
+	return operator == "and" || operator == "or" || operator == "not"
+}
+
+// applyFilter 应用单个过滤条件，operator 可带参数，如 "json_contains:$.tags"
 func (qb *QueryBuilder) applyFilter(column, operator string, value interface{}) {
+	operator, arg, _ := strings.Cut(operator, ":")
+
 	switch operator {
 	case "eq", "":
 		qb.db = qb.db.Where(fmt.Sprintf("%s = ?", column), value)
@@ -76,7 +171,23 @@ func (qb *QueryBuilder) applyFilter(column, operator string, value interface{})
 	case "lte":
 		qb.db = qb.db.Where(fmt.Sprintf("%s <= ?", column), value)
 	case "like":
-		qb.db = qb.db.Where(fmt.Sprintf("%s LIKE ?", column), fmt.Sprintf("%%%v%%", value))
+		qb.db = qb.db.Where(fmt.Sprintf("%s LIKE ? ESCAPE '!'", column), "%"+escapeLike(value)+"%")
+	case "starts_with":
+		qb.db = qb.db.Where(fmt.Sprintf("%s LIKE ? ESCAPE '!'", column), escapeLike(value)+"%")
+	case "ends_with":
+		qb.db = qb.db.Where(fmt.Sprintf("%s LIKE ? ESCAPE '!'", column), "%"+escapeLike(value))
+	case "ilike":
+		qb.db = qb.db.Where(qb.dialectILike(column), "%"+escapeLike(value)+"%")
+	case "regex":
+		qb.where(qb.dialectRegex(column, value))
+	case "match":
+		qb.where(qb.dialectMatch(column, value))
+	case "json_contains":
+		sql, vars := qb.dialectJSONContains(column, arg, value)
+		qb.where(sql, vars...)
+	case "overlap":
+		sql, vars := qb.dialectOverlap(column, value)
+		qb.where(sql, vars...)
 	case "in":
 		qb.db = qb.db.Where(fmt.Sprintf("%s IN ?", column), value)
 	case "not_in":
@@ -95,26 +206,99 @@ func (qb *QueryBuilder) applyFilter(column, operator string, value interface{})
 			v = v.Elem()
 		}
 
-		if v.Kind() != reflect.Struct {
-			return
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			// 标量区间：[min, max]
+			if v.Len() != 2 {
+				qb.db.AddError(fmt.Errorf("%w: between on %s requires exactly 2 values", ErrInvalidFilter, column))
+				return
+			}
+			qb.db = qb.db.Where(fmt.Sprintf("%s BETWEEN ? AND ?", column), v.Index(0).Interface(), v.Index(1).Interface())
+		case reflect.Struct:
+			// Range[T]
+			minField, maxField := v.FieldByName("Min"), v.FieldByName("Max")
+			if !minField.IsValid() || !maxField.IsValid() {
+				qb.db.AddError(fmt.Errorf("%w: between on %s requires [min, max] or {\"min\", \"max\"}", ErrInvalidFilter, column))
+				return
+			}
+			qb.applyRange(column, minField, maxField)
+		case reflect.Map:
+			// 动态过滤条件中 JSON 形式的 Range：{"min": 1, "max": 5}
+			if v.Type().Key().Kind() != reflect.String {
+				qb.db.AddError(fmt.Errorf("%w: between on %s requires [min, max] or {\"min\", \"max\"}", ErrInvalidFilter, column))
+				return
+			}
+			var minValue, maxValue reflect.Value
+			for _, key := range v.MapKeys() {
+				switch key.String() {
+				case "min":
+					minValue = v.MapIndex(key)
+				case "max":
+					maxValue = v.MapIndex(key)
+				default:
+					qb.db.AddError(fmt.Errorf("%w: between on %s: unknown key %q", ErrInvalidFilter, column, key.String()))
+					return
+				}
+			}
+			qb.applyRange(column, minValue, maxValue)
+		default:
+			qb.db.AddError(fmt.Errorf("%w: between on %s requires [min, max] or {\"min\", \"max\"}", ErrInvalidFilter, column))
 		}
+	default:
+		qb.db.AddError(fmt.Errorf("%w: unsupported operator %q on %s", ErrInvalidFilter, operator, column))
+	}
+}
 
-		minField := v.FieldByName("Min")
-		maxField := v.FieldByName("Max")
-
-		if minField.IsValid() && minField.Kind() == reflect.Ptr && !minField.IsNil() {
-			qb.db = qb.db.Where(fmt.Sprintf("%s >= ?", column), minField.Elem().Interface())
-		}
+// applyRange 应用范围的上下界，nil 或无效的边界忽略
+func (qb *QueryBuilder) applyRange(column string, min, max reflect.Value) {
+	if value, ok := boundValue(min); ok {
+		qb.db = qb.db.Where(fmt.Sprintf("%s >= ?", column), value)
+	}
+	if value, ok := boundValue(max); ok {
+		qb.db = qb.db.Where(fmt.Sprintf("%s <= ?", column), value)
+	}
+}
 
-		if maxField.IsValid() && maxField.Kind() == reflect.Ptr && !maxField.IsNil() {
-			qb.db = qb.db.Where(fmt.Sprintf("%s <= ?", column), maxField.Elem().Interface())
+// boundValue 取出范围边界的值，指针与 interface 解引用
+func boundValue(v reflect.Value) (interface{}, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, false
 		}
+		v = v.Elem()
 	}
+	if !v.IsValid() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// orderField 解析后的排序字段
+type orderField struct {
+	Column string
+	Desc   bool
 }
 
 func (qb *QueryBuilder) ApplyOrders(orders interface{}) *QueryBuilder {
+	for _, o := range qb.parseOrders(orders) {
+		qb.applyOrder(o)
+	}
+
+	return qb
+}
+
+func (qb *QueryBuilder) applyOrder(o orderField) {
+	direction := "ASC"
+	if o.Desc {
+		direction = "DESC"
+	}
+	qb.db = qb.db.Order(fmt.Sprintf("%s %s", qb.quote(o.Column), direction))
+}
+
+// parseOrders 解析排序结构体，非法的排序方向记录到 db 错误中
+func (qb *QueryBuilder) parseOrders(orders interface{}) []orderField {
 	if orders == nil {
-		return qb
+		return nil
 	}
 
 	ordersValue := reflect.ValueOf(orders)
@@ -123,11 +307,12 @@ func (qb *QueryBuilder) ApplyOrders(orders interface{}) *QueryBuilder {
 	}
 
 	if ordersValue.Kind() != reflect.Struct {
-		return qb
+		return nil
 	}
 
 	ordersType := ordersValue.Type()
 
+	var fields []orderField
 	for i := 0; i < ordersValue.NumField(); i++ {
 		field := ordersValue.Field(i)
 		fieldType := ordersType.Field(i)
@@ -140,38 +325,38 @@ func (qb *QueryBuilder) ApplyOrders(orders interface{}) *QueryBuilder {
 		switch field.Kind() {
 		case reflect.Ptr:
 			if field.Elem().Kind() != reflect.String {
-				qb.db.AddError(fmt.Errorf("order field %s must be string", fieldType.Name))
+				qb.db.AddError(fmt.Errorf("%w: order field %s must be string", ErrInvalidColumn, fieldType.Name))
 				continue
 			}
 			directionRaw = field.Elem().String()
 		case reflect.String:
 			directionRaw = field.String()
 		default:
-			qb.db.AddError(fmt.Errorf("order field %s must be string", fieldType.Name))
+			qb.db.AddError(fmt.Errorf("%w: order field %s must be string", ErrInvalidColumn, fieldType.Name))
 			continue
 		}
 
 		direction := strings.ToLower(strings.TrimSpace(directionRaw))
-		if direction == "" {
-			qb.db.AddError(fmt.Errorf("order field %s must be 'asc' or 'desc'", fieldType.Name))
+		if direction != "asc" && direction != "desc" {
+			qb.db.AddError(fmt.Errorf("%w: order field %s must be 'asc' or 'desc'", ErrInvalidColumn, fieldType.Name))
 			continue
 		}
 
-		if direction != "asc" && direction != "desc" {
-			qb.db.AddError(fmt.Errorf("order field %s must be 'asc' or 'desc'", fieldType.Name))
+		columnName := fieldColumn(fieldType)
+		if columnName == "" {
 			continue
 		}
 
-		jsonTag := fieldType.Tag.Get("json")
-		if jsonTag == "" || jsonTag == "-" {
+		column, err := qb.resolveColumn(columnName)
+		if err != nil {
+			qb.db.AddError(err)
 			continue
 		}
 
-		columnName := strings.Split(jsonTag, ",")[0]
-		qb.db = qb.db.Order(fmt.Sprintf("%s %s", columnName, strings.ToUpper(direction)))
+		fields = append(fields, orderField{Column: column, Desc: direction == "desc"})
 	}
 
-	return qb
+	return fields
 }
 
 func (qb *QueryBuilder) ApplyPagination(page interface{}) *QueryBuilder {
@@ -186,14 +371,21 @@ func (qb *QueryBuilder) ApplyPagination(page interface{}) *QueryBuilder {
 	return qb
 }
 
+// ApplyGroupBy 按列分组，列名需能在模型 schema 中解析
 func (qb *QueryBuilder) ApplyGroupBy(columns ...string) *QueryBuilder {
 	if len(columns) == 0 {
 		return qb
 	}
-	qb.db = qb.db.Group(strings.Join(columns, ", "))
+
+	quoted, ok := qb.quoteColumns(columns)
+	if !ok {
+		return qb
+	}
+	qb.db = qb.db.Group(strings.Join(quoted, ", "))
 	return qb
 }
 
+// ApplyHaving 追加 HAVING 原始条件，仅用于服务端构造的可信 SQL；客户端输入请使用 ApplyHavingConditions
 func (qb *QueryBuilder) ApplyHaving(condition string, args ...interface{}) *QueryBuilder {
 	if condition == "" {
 		return qb
@@ -202,14 +394,34 @@ func (qb *QueryBuilder) ApplyHaving(condition string, args ...interface{}) *Quer
 	return qb
 }
 
+// ApplySelect 选择列，列名需能在模型 schema 中解析
 func (qb *QueryBuilder) ApplySelect(columns ...string) *QueryBuilder {
 	if len(columns) == 0 {
 		return qb
 	}
-	qb.db = qb.db.Select(columns)
+
+	quoted, ok := qb.quoteColumns(columns)
+	if !ok {
+		return qb
+	}
+	qb.db = qb.db.Select(quoted)
 	return qb
 }
 
+// quoteColumns 批量解析列名，存在非法列名时记录错误并返回 false
+func (qb *QueryBuilder) quoteColumns(columns []string) ([]string, bool) {
+	quoted := make([]string, len(columns))
+	for i, name := range columns {
+		column, err := qb.quoteColumn(name)
+		if err != nil {
+			qb.db.AddError(err)
+			return nil, false
+		}
+		quoted[i] = column
+	}
+	return quoted, true
+}
+
 func (qb *QueryBuilder) Query(result interface{}) error {
 	return qb.db.Find(result).Error
 }
@@ -217,7 +429,8 @@ func (qb *QueryBuilder) Query(result interface{}) error {
 func (qb *QueryBuilder) QueryWithCount(result interface{}) (int64, error) {
 	var count int64
 
-	countDB := qb.db.Session(&gorm.Session{})
+	// 统计总数时去掉分页条件，否则第二页之后 COUNT 会返回 0
+	countDB := qb.db.Session(&gorm.Session{}).Offset(-1).Limit(-1)
 	if err := countDB.Count(&count).Error; err != nil {
 		return 0, err
 	}
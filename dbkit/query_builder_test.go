@@ -0,0 +1,110 @@
+package dbkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestApplyFiltersBetween(t *testing.T) {
+	db := dryRunDB(t)
+	lo, hi := 18, 65
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "slice", value: []interface{}{18, 65}, want: "`age` BETWEEN 18 AND 65"},
+		{name: "range", value: Range[int]{Min: &lo, Max: &hi}, want: "`age` >= 18 AND `age` <= 65"},
+		{name: "range min only", value: &Range[int]{Min: &lo}, want: "WHERE `age` >= 18"},
+		{name: "json object", value: map[string]interface{}{"min": 18, "max": 65}, want: "`age` >= 18 AND `age` <= 65"},
+		{name: "wrong length", value: []interface{}{18}, wantErr: true},
+		{name: "unknown key", value: map[string]interface{}{"from": 18}, wantErr: true},
+		{name: "scalar", value: 18, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			sql := toSQL(t, db, func(tx *gorm.DB) *gorm.DB {
+				qb := NewQueryBuilder(tx.Model(&testUser{}))
+				qb.applyFilter("`age`", "between", tt.value)
+				res := qb.GetDB().Find(&[]testUser{})
+				err = res.Error
+				return res
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidFilter) {
+					t.Fatalf("want ErrInvalidFilter, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(sql, tt.want) {
+				t.Fatalf("sql %q does not contain %q", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDynamicFilterBetweenObject(t *testing.T) {
+	db := dryRunDB(t)
+	sql := toSQL(t, db, func(tx *gorm.DB) *gorm.DB {
+		qb := NewQueryBuilder(tx.Model(&testUser{}))
+		qb.ApplyDynamicFilter(DynamicFilter{{Field: "age", Op: "between", Value: map[string]interface{}{"min": 1.0, "max": 5.0}}})
+		return qb.GetDB().Find(&[]testUser{})
+	})
+	if !strings.Contains(sql, "`age` >= 1 AND `age` <= 5") {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+}
+
+func TestApplyFiltersOperatorMap(t *testing.T) {
+	db := dryRunDB(t)
+
+	type filters struct {
+		AgeOps  map[string]interface{} `json:"age_ops" column:"age"`
+		NameOps map[string]interface{} `json:"name_ops" column:"name" filter:"eq,starts_with"`
+	}
+
+	tests := []struct {
+		name    string
+		filters filters
+		want    string
+		wantErr bool
+	}{
+		{name: "entity ops", filters: filters{AgeOps: map[string]interface{}{"gte": 18, "lt": 65}}, want: "`age` >= 18 AND `age` < 65"},
+		{name: "not in entity ops", filters: filters{AgeOps: map[string]interface{}{"ne": 30}}, wantErr: true},
+		{name: "tag list", filters: filters{NameOps: map[string]interface{}{"starts_with": "a"}}, want: "`name` LIKE 'a%'"},
+		{name: "not in tag list", filters: filters{NameOps: map[string]interface{}{"regex": ".*"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			sql := toSQL(t, db, func(tx *gorm.DB) *gorm.DB {
+				res := NewQueryBuilder(tx.Model(&testUser{})).ApplyFilters(tt.filters).GetDB().Find(&[]testUser{})
+				err = res.Error
+				return res
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidFilter) {
+					t.Fatalf("want ErrInvalidFilter, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(sql, tt.want) {
+				t.Fatalf("sql %q does not contain %q", sql, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package dbkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPageSize 查询串只传 page 时的每页条数
+const defaultPageSize = 20
+
+// 查询串中的保留参数，其余参数均视为过滤条件
+var reservedQueryKeys = map[string]bool{
+	"page":            true,
+	"page_size":       true,
+	"cursor":          true,
+	"with_total":      true,
+	"sort":            true,
+	"include_deleted": true,
+	"only_deleted":    true,
+	"fields":          true,
+	"include":         true,
+}
+
+// BindQueryString 将查询串绑定到查询请求：
+//
+//	name=张              -> Filters 中 json 名为 name 的字段（按其 filter 标签过滤）
+//	age[gte]=18          -> Filters 中列为 age 且 filter 为 gte 的字段，没有时作为动态过滤条件（受实体 ops 标签约束）
+//	age_ops[ne]=30       -> Filters 中 json 名为 age_ops 的操作符映射字段
+//	id[in]=a,b           -> 列表值以逗号分隔
+//	sort=-age,id         -> Orders 中对应字段，"-" 前缀为降序
+//	page=2&page_size=10  -> Page，cursor、with_total 同理
+//	include_deleted=true -> 同时查询已软删除的记录，only_deleted 同理
+//	fields=id,name       -> 只返回指定的字段
+//	include=orders:5     -> 一并加载的关联，":" 后为每条记录最多加载的条数
+func BindQueryString[F any, O any](values url.Values, req *BaseQueryRequest[F, O]) error {
+	if err := bindPage(values, req); err != nil {
+		return err
+	}
+
+	if err := bindSort(values.Get("sort"), &req.Orders); err != nil {
+		return err
+	}
+
+	if raw := values.Get("fields"); raw != "" {
+		req.Fields = splitFields(raw)
+	}
+	if raw := values.Get("include"); raw != "" {
+		includes, err := parseIncludes(raw)
+		if err != nil {
+			return err
+		}
+		req.Include = includes
+	}
+
+	for key, dst := range map[string]*bool{"include_deleted": &req.IncludeDeleted, "only_deleted": &req.OnlyDeleted} {
+		if raw := values.Get(key); raw != "" {
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("%w: %s must be a boolean", ErrInvalidFilter, key)
+			}
+			*dst = b
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if !reservedQueryKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	filters := reflect.ValueOf(&req.Filters).Elem()
+	for _, key := range keys {
+		name, op, hasOp := parseQueryKey(key)
+		if name == "" {
+			return fmt.Errorf("%w: invalid query parameter %q", ErrInvalidFilter, key)
+		}
+
+		if !hasOp {
+			field, ok := findFilterField(filters, func(f reflect.StructField) bool {
+				return jsonFieldName(f) == name
+			})
+			if !ok {
+				return fmt.Errorf("%w: unknown filter %q", ErrInvalidFilter, name)
+			}
+			if err := setFromStrings(field, values[key]); err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidFilter, key, err)
+			}
+			continue
+		}
+
+		// 过滤结构体中列名与操作符都匹配的字段
+		field, ok := findFilterField(filters, func(f reflect.StructField) bool {
+			return fieldColumn(f) == name && filterOp(f) == op && !isMapField(f.Type)
+		})
+		if ok {
+			if err := setFromStrings(field, values[key]); err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidFilter, key, err)
+			}
+			continue
+		}
+
+		// 操作符映射字段：age_ops[ne]=30
+		field, ok = findFilterField(filters, func(f reflect.StructField) bool {
+			return isMapField(f.Type) && jsonFieldName(f) == name
+		})
+		if ok {
+			if err := setMapEntry(field, op, queryValue(op, values[key])); err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidFilter, key, err)
+			}
+			continue
+		}
+
+		req.Where = append(req.Where, DynamicCondition{Field: name, Op: op, Value: queryValue(op, values[key])})
+	}
+
+	return nil
+}
+
+// parseQueryKey 拆分 "age[gte]" 为字段名与操作符
+func parseQueryKey(key string) (name, op string, hasOp bool) {
+	open := strings.IndexByte(key, '[')
+	if open < 0 {
+		return key, "", false
+	}
+	if !strings.HasSuffix(key, "]") || open == 0 {
+		return "", "", false
+	}
+	return key[:open], key[open+1 : len(key)-1], true
+}
+
+// bindPage 绑定分页参数，只传 page 时使用默认每页条数
+func bindPage[F any, O any](values url.Values, req *BaseQueryRequest[F, O]) error {
+	page := &Page{}
+	set := false
+
+	for _, p := range []struct {
+		key string
+		dst *int
+	}{{"page", &page.PageNum}, {"page_size", &page.PageSize}} {
+		if raw := values.Get(p.key); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("%w: %s must be a positive integer", ErrInvalidFilter, p.key)
+			}
+			*p.dst = n
+			set = true
+		}
+	}
+
+	if values.Has("cursor") {
+		cursor := values.Get("cursor")
+		page.Cursor = &cursor
+		set = true
+	}
+
+	if raw := values.Get("with_total"); raw != "" {
+		withTotal, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%w: with_total must be a boolean", ErrInvalidFilter)
+		}
+		page.WithTotal = &withTotal
+		set = true
+	}
+
+	if !set {
+		return nil
+	}
+	if page.PageSize == 0 {
+		page.PageSize = defaultPageSize
+	}
+	if page.PageNum == 0 && page.Cursor == nil {
+		page.PageNum = 1
+	}
+	req.Page = page
+	return nil
+}
+
+// bindSort 将 "-age,id" 绑定到排序结构体中 json 名对应的字段
+func bindSort(raw string, orders interface{}) error {
+	if raw == "" {
+		return nil
+	}
+
+	ordersValue := reflect.ValueOf(orders).Elem()
+	if ordersValue.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: sorting is not supported", ErrInvalidFilter)
+	}
+
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		direction := "asc"
+		if strings.HasPrefix(item, "-") {
+			item, direction = item[1:], "desc"
+		}
+		item = strings.TrimPrefix(item, "+")
+		if item == "" {
+			continue
+		}
+
+		field, ok := findFilterField(ordersValue, func(f reflect.StructField) bool {
+			return jsonFieldName(f) == item
+		})
+		if !ok {
+			return &ColumnError{Column: item}
+		}
+		if err := setFromStrings(field, []string{direction}); err != nil {
+			return fmt.Errorf("%w: sort %s: %v", ErrInvalidFilter, item, err)
+		}
+	}
+	return nil
+}
+
+// findFilterField 在结构体（含嵌入结构体）中查找满足条件的字段，跳过逻辑分组与动态过滤字段
+func findFilterField(v reflect.Value, match func(reflect.StructField) bool) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if field, ok := findFilterField(v.Field(i), match); ok {
+				return field, true
+			}
+			continue
+		}
+		if isLogicOperator(sf.Tag.Get("filter")) || sf.Type == reflect.TypeOf(DynamicFilter{}) {
+			continue
+		}
+		if match(sf) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// jsonFieldName 结构体字段的 json 名称
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// filterOp 字段的过滤操作符，未设置时为 eq
+func filterOp(f reflect.StructField) string {
+	if op := f.Tag.Get("filter"); op != "" {
+		return op
+	}
+	return "eq"
+}
+
+func isMapField(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+// setMapEntry 向操作符映射字段写入一项
+func setMapEntry(field reflect.Value, op string, value interface{}) error {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	v, err := mapEntryValue(value, field.Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	if field.IsNil() {
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+	field.SetMapIndex(reflect.ValueOf(op), v)
+	return nil
+}
+
+// mapEntryValue 将查询串的值转换为映射的值类型，如 map[string]int 中的 int
+func mapEntryValue(value interface{}, t reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := mapEntryValue(value, t.Elem())
+		if err != nil {
+			return v, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case reflect.Slice:
+		if items, ok := value.([]string); ok {
+			slice := reflect.MakeSlice(t, len(items), len(items))
+			for i, item := range items {
+				elem, err := mapEntryValue(item, t.Elem())
+				if err != nil {
+					return v, err
+				}
+				slice.Index(i).Set(elem)
+			}
+			return slice, nil
+		}
+	default:
+		if converted := reflect.ValueOf(coerceValue(value, t)); converted.Type().AssignableTo(t) {
+			return converted, nil
+		}
+	}
+	return v, fmt.Errorf("cannot assign %T to %s", value, t)
+}
+
+// queryValue 查询串中的值：列表类操作符按逗号拆分，其余取原字符串
+func queryValue(op string, raw []string) interface{} {
+	switch op {
+	case "in", "not_in", "between", "overlap":
+		var items []string
+		for _, r := range raw {
+			items = append(items, strings.Split(r, ",")...)
+		}
+		return items
+	}
+	if len(raw) == 0 {
+		return ""
+	}
+	return raw[len(raw)-1]
+}
+
+// setFromStrings 将查询串的值解析到字段类型，切片字段按逗号拆分
+func setFromStrings(field reflect.Value, raw []string) error {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := setFromStrings(elem.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		var items []string
+		for _, r := range raw {
+			items = append(items, strings.Split(r, ",")...)
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setFromStrings(slice.Index(i), []string{item}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	s := raw[len(raw)-1]
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Interface:
+		field.Set(reflect.ValueOf(s))
+	default:
+		if field.Type() == reflect.TypeOf(time.Time{}) {
+			t, ok := parseTime(s, nil).(time.Time)
+			if !ok {
+				return fmt.Errorf("invalid time %q", s)
+			}
+			field.Set(reflect.ValueOf(t))
+			return nil
+		}
+		// 其他类型（如 Range）按 JSON 解析
+		return json.Unmarshal([]byte(s), field.Addr().Interface())
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package dbkit
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestBindQueryStringTypedOperatorMaps(t *testing.T) {
+	type filters struct {
+		AgeOps  map[string]int         `json:"age" column:"age"`
+		IDOps   map[string][]uint      `json:"id" column:"id"`
+		NameOps map[string]*string     `json:"name" column:"name"`
+		AnyOps  map[string]interface{} `json:"tenant_id" column:"tenant_id"`
+	}
+
+	values, _ := url.ParseQuery("age[gte]=18&age[lt]=60&id[in]=1,2&name[ne]=bob&tenant_id[eq]=3")
+	var req BaseQueryRequest[filters, struct{}]
+	if err := BindQueryString(values, &req); err != nil {
+		t.Fatalf("BindQueryString: %v", err)
+	}
+
+	if want := map[string]int{"gte": 18, "lt": 60}; !reflect.DeepEqual(req.Filters.AgeOps, want) {
+		t.Errorf("age = %v, want %v", req.Filters.AgeOps, want)
+	}
+	if want := map[string][]uint{"in": {1, 2}}; !reflect.DeepEqual(req.Filters.IDOps, want) {
+		t.Errorf("id = %v, want %v", req.Filters.IDOps, want)
+	}
+	if name := req.Filters.NameOps["ne"]; name == nil || *name != "bob" {
+		t.Errorf("name = %v, want bob", req.Filters.NameOps)
+	}
+	if want := map[string]interface{}{"eq": "3"}; !reflect.DeepEqual(req.Filters.AnyOps, want) {
+		t.Errorf("tenant_id = %v, want %v", req.Filters.AnyOps, want)
+	}
+
+	for _, query := range []string{"age[gte]=abc", "age[in]=1,2", "id[in]=1,-2"} {
+		values, _ := url.ParseQuery(query)
+		var req BaseQueryRequest[filters, struct{}]
+		if err := BindQueryString(values, &req); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: err = %v, want ErrInvalidFilter", query, err)
+		}
+	}
+}
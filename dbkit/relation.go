@@ -0,0 +1,291 @@
+package dbkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidInclude = errors.New("invalid include")
+
+// Include 查询时一并加载的关联，JSON 中可写为 "department" 或 {"name": "orders", "limit": 5}。
+// 关联字段的 include 标签：
+//
+//	Orders []Order `gorm:"foreignKey:UserID;->" json:"orders,omitempty" include:"max=20"` // 每条记录最多加载 20 条
+//	Secrets []Secret `gorm:"foreignKey:UserID" json:"-" include:"-"`                      // 禁止加载与过滤
+type Include struct {
+	Name  string `json:"name"`            // 关联的 json 名或字段名
+	Limit int    `json:"limit,omitempty"` // 一对多关联每条记录最多加载的条数，不能超过标签中的 max
+}
+
+func (i *Include) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*i = Include{Name: name}
+		return nil
+	}
+	type plain Include
+	return json.Unmarshal(data, (*plain)(i))
+}
+
+// IncludeRequest 携带关联加载的查询请求
+type IncludeRequest interface {
+	GetIncludes() []Include
+}
+
+// parseIncludes 解析查询串中的 "department,orders:5"
+func parseIncludes(raw string) ([]Include, error) {
+	var includes []Include
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, limit, hasLimit := strings.Cut(item, ":")
+		inc := Include{Name: name}
+		if hasLimit {
+			n, err := strconv.Atoi(limit)
+			if err != nil {
+				return nil, fmt.Errorf("%w: limit of %q must be an integer", ErrInvalidInclude, name)
+			}
+			inc.Limit = n
+		}
+		includes = append(includes, inc)
+	}
+	return includes, nil
+}
+
+// lookupRelation 按 json 名或字段名查找关联，include:"-" 的关联不可使用
+func lookupRelation(sch *schema.Schema, name string) *schema.Relationship {
+	if sch == nil {
+		return nil
+	}
+	for _, rel := range sch.Relationships.Relations {
+		if rel.Field.Tag.Get("include") == "-" {
+			continue
+		}
+		if jsonName(rel.Field) == name || rel.Name == name {
+			return rel
+		}
+	}
+	return nil
+}
+
+// includeMax 关联字段 include 标签中的 max，未设置时为 0
+func includeMax(rel *schema.Relationship) int {
+	for _, opt := range strings.Split(rel.Field.Tag.Get("include"), ",") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(opt), "max="); ok {
+			n, _ := strconv.Atoi(value)
+			return n
+		}
+	}
+	return 0
+}
+
+// resolveInclude 校验要加载的关联，返回每条记录最多加载的条数（0 为不限制），未指定 limit 时使用标签中的 max
+func resolveInclude(sch *schema.Schema, inc Include) (*schema.Relationship, int, error) {
+	rel := lookupRelation(sch, inc.Name)
+	if rel == nil {
+		return nil, 0, fmt.Errorf("%w: unknown association %q", ErrInvalidInclude, inc.Name)
+	}
+
+	limit := inc.Limit
+	if limit < 0 {
+		return nil, 0, fmt.Errorf("%w: limit of %q must be positive", ErrInvalidInclude, inc.Name)
+	}
+	if rel.Type != schema.HasMany {
+		if limit > 0 {
+			return nil, 0, fmt.Errorf("%w: limit is only supported on has many association %q", ErrInvalidInclude, inc.Name)
+		}
+		return rel, 0, nil
+	}
+
+	max := includeMax(rel)
+	if max > 0 && limit > max {
+		return nil, 0, fmt.Errorf("%w: limit of %q must be less than or equal to %d", ErrInvalidInclude, inc.Name, max)
+	}
+	if limit == 0 {
+		limit = max
+	}
+	return rel, limit, nil
+}
+
+// applyIncludes 预加载请求中的关联。每个关联单独查询而不是 JOIN，主查询的列名、分页与计数不受影响
+func (qb *QueryBuilder) applyIncludes(req QueryRequest) *QueryBuilder {
+	r, ok := req.(IncludeRequest)
+	if !ok || len(r.GetIncludes()) == 0 {
+		return qb
+	}
+
+	sch := qb.modelSchema()
+	for _, inc := range r.GetIncludes() {
+		rel, limit, err := resolveInclude(sch, inc)
+		if err != nil {
+			_ = qb.db.AddError(err)
+			return qb
+		}
+		if limit > 0 {
+			if !windowFunctions(qb.db) {
+				_ = qb.db.AddError(fmt.Errorf("%w: limit of %q requires window functions (MySQL 8.0+, MariaDB 10.2+)", ErrInvalidInclude, inc.Name))
+				return qb
+			}
+			qb.db = qb.db.Preload(rel.Name, limitPerParent(rel, limit))
+		} else {
+			qb.db = qb.db.Preload(rel.Name)
+		}
+	}
+	return qb
+}
+
+// limitPerParent 一对多关联按外键分区编号，每条记录只加载前 limit 条（按主键排序），需要数据库支持窗口函数。
+// 编号子查询中显式加上本页父记录的外键条件（父记录由 GORM 预加载时传入），只为这些记录的关联编号，而不是整张关联表
+func limitPerParent(rel *schema.Relationship, limit int) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		child := rel.FieldSchema
+		var keys, partition []string
+		var parentFields []*schema.Field
+		for _, ref := range rel.References {
+			if ref.OwnPrimaryKey {
+				keys = append(keys, ref.ForeignKey.DBName)
+				partition = append(partition, tx.Statement.Quote(ref.ForeignKey.DBName))
+				parentFields = append(parentFields, ref.PrimaryKey)
+			}
+		}
+		order := "1"
+		if pk := child.PrioritizedPrimaryField; pk != nil {
+			order = tx.Statement.Quote(pk.DBName)
+		}
+
+		numbered := tx.Session(&gorm.Session{NewDB: true}).
+			Model(reflect.New(child.ModelType).Interface()).
+			Select(fmt.Sprintf("%s.*, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS dbkit_rn",
+				tx.Statement.Quote(child.Table), strings.Join(partition, ", "), order))
+		if tx.Statement.Unscoped {
+			numbered = numbered.Unscoped()
+		}
+		if tx.Statement.ReflectValue.IsValid() {
+			_, parents := schema.GetIdentityFieldValuesMap(tx.Statement.Context, tx.Statement.ReflectValue, parentFields)
+			column, values := schema.ToQueryValues(child.Table, keys, parents)
+			numbered = numbered.Where(clause.IN{Column: column, Values: values})
+		}
+
+		// 派生表沿用关联表名作别名，GORM 追加的外键条件与软删除条件按该别名引用列
+		tx = tx.Table("(?) AS "+tx.Statement.Quote(child.Table), numbered)
+		tx.Statement.Table = child.Table
+		return tx.Where("dbkit_rn <= ?", limit).Order(order)
+	}
+}
+
+// windowFunctions 数据库是否支持窗口函数：MySQL 8.0+、MariaDB 10.2+，未知版本与其他数据库视为支持
+func windowFunctions(db *gorm.DB) bool {
+	d, ok := db.Dialector.(*mysql.Dialector)
+	if !ok || d.ServerVersion == "" {
+		return true
+	}
+
+	version := d.ServerVersion
+	if strings.Contains(version, "MariaDB") {
+		// 老版本协议中 MariaDB 的版本号带有 5.5.5- 前缀
+		version = strings.TrimPrefix(version, "5.5.5-")
+		return versionAtLeast(version, 10, 2)
+	}
+	return versionAtLeast(version, 8, 0)
+}
+
+// versionAtLeast 版本号（如 8.0.12）是否不低于 major.minor
+func versionAtLeast(version string, major, minor int) bool {
+	var v1, v2 int
+	if _, err := fmt.Sscanf(version, "%d.%d", &v1, &v2); err != nil {
+		return true
+	}
+	return v1 > major || (v1 == major && v2 >= minor)
+}
+
+// parentColumns 加载关联时主表需要查询的列（关联条件中主表一侧的列）
+func parentColumns(rel *schema.Relationship) []string {
+	var columns []string
+	for _, ref := range rel.References {
+		switch {
+		case ref.OwnPrimaryKey:
+			columns = append(columns, ref.PrimaryKey.DBName)
+		case ref.PrimaryValue == "" && rel.JoinTable == nil:
+			columns = append(columns, ref.ForeignKey.DBName)
+		}
+	}
+	return columns
+}
+
+// applyRelationFilter 按关联字段过滤（如 department.name），以 EXISTS 子查询实现，
+// 一对多、多对多关联中任一记录满足条件即可。apply 在子查询上应用条件，column 为加了引号的关联表列名
+func (qb *QueryBuilder) applyRelationFilter(path string, apply func(sub *QueryBuilder, column string)) {
+	name, columnName, _ := strings.Cut(path, ".")
+	sch := qb.modelSchema()
+	rel := lookupRelation(sch, name)
+	if rel == nil {
+		_ = qb.db.AddError(&ColumnError{Column: path})
+		return
+	}
+	field := lookupField(rel.FieldSchema, columnName)
+	if field == nil {
+		_ = qb.db.AddError(&ColumnError{Column: path})
+		return
+	}
+
+	// 关联表以关联名为别名，避免自关联时与主表同名
+	alias := rel.Name
+	sub := &QueryBuilder{sch: rel.FieldSchema}
+	sub.db = qb.newSession().
+		Model(reflect.New(rel.FieldSchema.ModelType).Interface()).
+		Table(qb.quote(rel.FieldSchema.Table) + " AS " + alias)
+	sub.joinParent(sch, rel, alias)
+	apply(sub, sub.quote(alias+"."+field.DBName))
+
+	if err := sub.db.Error; err != nil {
+		_ = qb.db.AddError(err)
+		return
+	}
+	qb.db = qb.db.Where("EXISTS (?)", sub.db.Select("1"))
+}
+
+// joinParent 追加关联表与主表的关联条件，多对多关联经由中间表
+func (qb *QueryBuilder) joinParent(parent *schema.Schema, rel *schema.Relationship, alias string) {
+	column := func(table, name string) string {
+		return qb.quote(table + "." + name)
+	}
+
+	if rel.JoinTable == nil {
+		for _, ref := range rel.References {
+			switch {
+			case ref.OwnPrimaryKey:
+				qb.db = qb.db.Where(column(alias, ref.ForeignKey.DBName) + " = " + column(parent.Table, ref.PrimaryKey.DBName))
+			case ref.PrimaryValue != "":
+				qb.db = qb.db.Where(column(alias, ref.ForeignKey.DBName)+" = ?", ref.PrimaryValue)
+			default:
+				qb.db = qb.db.Where(column(alias, ref.PrimaryKey.DBName) + " = " + column(parent.Table, ref.ForeignKey.DBName))
+			}
+		}
+		return
+	}
+
+	joinTable := rel.JoinTable.Table
+	var on []string
+	for _, ref := range rel.References {
+		switch {
+		case ref.OwnPrimaryKey:
+			qb.db = qb.db.Where(column(joinTable, ref.ForeignKey.DBName) + " = " + column(parent.Table, ref.PrimaryKey.DBName))
+		case ref.PrimaryValue != "":
+			qb.db = qb.db.Where(column(joinTable, ref.ForeignKey.DBName)+" = ?", ref.PrimaryValue)
+		default:
+			on = append(on, column(joinTable, ref.ForeignKey.DBName)+" = "+column(alias, ref.PrimaryKey.DBName))
+		}
+	}
+	qb.db = qb.db.Joins("JOIN " + qb.quote(joinTable) + " ON " + strings.Join(on, " AND "))
+}
@@ -0,0 +1,99 @@
+package dbkit
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// testOrder 表名为保留字，验证派生表别名加引号
+type testOrder struct {
+	ID     uint    `gorm:"primaryKey" json:"id"`
+	UserID uint    `json:"user_id"`
+	Amount float64 `json:"amount"`
+	Note   string  `json:"-"`
+}
+
+func (testOrder) TableName() string { return "order" }
+
+type testOwner struct {
+	ID     uint        `gorm:"primaryKey" json:"id"`
+	Name   string      `json:"name"`
+	Orders []testOrder `gorm:"foreignKey:UserID;->" json:"orders,omitempty" include:"max=5"`
+}
+
+func (testOwner) TableName() string { return "user" }
+
+type ownerRequest = BaseQueryRequest[struct{}, struct{}]
+
+func TestIncludeLimitPerParent(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.result = func(query string) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "count(*)"):
+			return []string{"count(*)"}, [][]driver.Value{{int64(2)}}
+		case strings.Contains(query, "FROM `user`"):
+			return []string{"id", "name"}, [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}}
+		}
+		return nil, nil
+	}
+
+	req := &ownerRequest{Include: []Include{{Name: "orders", Limit: 2}}}
+	if _, err := QueryPage[testOwner](db, req); err != nil {
+		t.Fatalf("QueryPage: %v", err)
+	}
+
+	want := "SELECT * FROM (SELECT `order`.*, ROW_NUMBER() OVER (PARTITION BY `user_id` ORDER BY `id`) AS dbkit_rn " +
+		"FROM `order` WHERE `order`.`user_id` IN (?,?)) AS `order` WHERE dbkit_rn <= ? AND `order`.`user_id` IN (?,?) ORDER BY `id` | 1, 2, 2, 1, 2"
+	if sql := rec.last("ROW_NUMBER"); sql != want {
+		t.Fatalf("unexpected preload sql:\n got: %s\nwant: %s", sql, want)
+	}
+}
+
+func TestIncludeLimitRequiresWindowFunctions(t *testing.T) {
+	db, _ := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+
+	tests := []struct {
+		version string
+		ok      bool
+	}{
+		{version: "5.7.44", ok: false},
+		{version: "8.0.12", ok: true},
+		{version: "5.5.5-10.1.48-MariaDB", ok: false},
+		{version: "10.6.16-MariaDB", ok: true},
+	}
+	for _, tt := range tests {
+		db.Dialector.(*mysql.Dialector).ServerVersion = tt.version
+
+		_, err := QueryPage[testOwner](db, &ownerRequest{Include: []Include{{Name: "orders"}}})
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.version, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidInclude) {
+			t.Errorf("%s: want ErrInvalidInclude, got %v", tt.version, err)
+		}
+	}
+}
+
+func TestRelationFilterRejectsHiddenColumns(t *testing.T) {
+	db := dryRunDB(t)
+	note := "x"
+
+	var err error
+	toSQL(t, db, func(tx *gorm.DB) *gorm.DB {
+		qb := NewQueryBuilder(tx.Model(&testOwner{}))
+		qb.ApplyFilters(&struct {
+			Note *string `column:"orders.note"`
+		}{Note: &note})
+		res := qb.GetDB().Find(&[]testOwner{})
+		err = res.Error
+		return res
+	})
+	if !errors.Is(err, ErrInvalidColumn) {
+		t.Fatalf("err = %v, want ErrInvalidColumn", err)
+	}
+}
@@ -0,0 +1,162 @@
+package dbkit
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Route 资源路由名称，用于按路由启用或禁用
+type Route string
+
+const (
+	RouteQuery       Route = "query"        // POST {path}/query
+	RouteOne         Route = "one"          // POST {path}/one
+	RouteGet         Route = "get"          // GET {path}/:id
+	RouteCreate      Route = "create"       // POST {path}
+	RouteUpdate      Route = "update"       // POST {path}/update
+	RouteDelete      Route = "delete"       // POST {path}/delete
+	RouteBatchCreate Route = "batch_create" // POST {path}/batch
+	RouteBatchUpdate Route = "batch_update" // POST {path}/batch-update
+	RouteBatchDelete Route = "batch_delete" // POST {path}/batch-delete
+	RouteStats       Route = "stats"        // POST {path}/stats
+
+	RouteList       Route = "list"         // GET {path}?age[gte]=18&sort=-age&page=2
+	RouteReplace    Route = "replace"      // PUT {path}/:id
+	RoutePatch      Route = "patch"        // PATCH {path}/:id
+	RouteDeleteByID Route = "delete_by_id" // DELETE {path}/:id
+
+	// 仅在实体有 gorm.DeletedAt 字段时挂载
+	RouteRestore Route = "restore" // POST {path}/:id/restore
+	RoutePurge   Route = "purge"   // DELETE {path}/:id/purge
+
+	// 仅在 DB 开启审计（EnableAudit）时挂载
+	RouteHistory Route = "history" // GET {path}/:id/history
+)
+
+// ResourceOptions 资源注册配置
+type ResourceOptions struct {
+	DB          *gorm.DB
+	Only        []Route           // 非空时只挂载这些路由
+	Except      []Route           // 不挂载的路由
+	GenerateID  bool              // 创建时为空的字符串主键生成32位UUID
+	BatchSize   int               // 批量创建的批次大小，默认 100
+	Options     []Option          // 传给所有处理器的选项，如 WithHooks、WithTotalMode
+	Middlewares []gin.HandlerFunc // 作用于该资源所有路由的中间件
+}
+
+// enabled 判断路由是否需要挂载
+func (o *ResourceOptions) enabled(route Route) bool {
+	for _, r := range o.Except {
+		if r == route {
+			return false
+		}
+	}
+	if len(o.Only) == 0 {
+		return true
+	}
+	for _, r := range o.Only {
+		if r == route {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterResource 为实体挂载一组 CRUD 路由，返回资源的路由组以便追加自定义路由。
+// F、O 为过滤与排序结构体，U 为 /update 与 PATCH /:id 使用的更新结构体
+func RegisterResource[T any, F any, O any, U any](router gin.IRouter, path string, opts ResourceOptions) *gin.RouterGroup {
+	if opts.DB == nil {
+		panic("dbkit: RegisterResource requires ResourceOptions.DB")
+	}
+
+	db := opts.DB
+	group := router.Group(path, opts.Middlewares...)
+
+	options := opts.Options
+	if opts.GenerateID {
+		options = append([]Option{WithGeneratedID(primaryKeySetter[T](db))}, options...)
+	}
+	if opts.enabled(RouteQuery) {
+		group.POST("/query", GenericQueryHandler[T, F, O](db, options...))
+	}
+	if opts.enabled(RouteOne) {
+		group.POST("/one", GenericGetOneHandler[T, F, O](db, options...))
+	}
+	if opts.enabled(RouteGet) {
+		group.GET("/:id", GenericGetByIDHandler[T](db, options...))
+	}
+	if opts.enabled(RouteCreate) {
+		group.POST("", GenericCreateHandler[T](db, options...))
+	}
+	if opts.enabled(RouteUpdate) {
+		group.POST("/update", GenericUpdateHandler[T, F, U](db, options...))
+	}
+	if opts.enabled(RouteDelete) {
+		group.POST("/delete", GenericDeleteHandler[T, F](db, options...))
+	}
+	if opts.enabled(RouteBatchCreate) {
+		group.POST("/batch", GenericBatchCreateHandler[T](db, opts.BatchSize, options...))
+	}
+	if opts.enabled(RouteBatchUpdate) {
+		group.POST("/batch-update", GenericBatchUpdateHandler[T, U](db, options...))
+	}
+	if opts.enabled(RouteBatchDelete) {
+		group.POST("/batch-delete", GenericBatchDeleteHandler[T](db, options...))
+	}
+	if opts.enabled(RouteStats) {
+		group.POST("/stats", GenericStatsHandler[T, F, O](db, options...))
+	}
+
+	// RESTful 风格路由
+	if opts.enabled(RouteList) {
+		group.GET("", GenericListHandler[T, F, O](db, options...))
+	}
+	if opts.enabled(RouteReplace) {
+		group.PUT("/:id", GenericReplaceByIDHandler[T](db, options...))
+	}
+	if opts.enabled(RoutePatch) {
+		group.PATCH("/:id", GenericPatchByIDHandler[T, U](db, options...))
+	}
+	if opts.enabled(RouteDeleteByID) {
+		group.DELETE("/:id", GenericDeleteByIDHandler[T](db, options...))
+	}
+
+	// 软删除
+	if supportsSoftDelete[T](db) {
+		if opts.enabled(RouteRestore) {
+			group.POST("/:id/restore", GenericRestoreByIDHandler[T](db, options...))
+		}
+		if opts.enabled(RoutePurge) {
+			group.DELETE("/:id/purge", GenericPurgeByIDHandler[T](db, options...))
+		}
+	}
+
+	// 审计日志
+	if auditEnabled(db) && opts.enabled(RouteHistory) {
+		group.GET("/:id/history", GenericAuditHistoryHandler[T](db, options...))
+	}
+
+	return group
+}
+
+// primaryKeySetter 返回为空的字符串主键赋值的函数，已有值或非字符串主键时不做处理
+func primaryKeySetter[T any](db *gorm.DB) func(*T, string) {
+	return func(entity *T, id string) {
+		sch, err := parseSchema(db, entity)
+		if err != nil || sch.PrioritizedPrimaryField == nil {
+			return
+		}
+
+		pk := sch.PrioritizedPrimaryField
+		if pk.FieldType.Kind() != reflect.String {
+			return
+		}
+
+		rv := reflect.ValueOf(entity).Elem()
+		if _, isZero := pk.ValueOf(db.Statement.Context, rv); isZero {
+			_ = pk.Set(db.Statement.Context, rv, id)
+		}
+	}
+}
@@ -1,11 +1,32 @@
 package dbkit
 
+import "encoding/json"
+
 type PageResponse[T any] struct {
-	Code  int       `json:"code"`
-	Msg   string    `json:"msg"`
-	Data  []T       `json:"data"`
-	Total int64     `json:"total"`
-	Page  *PageInfo `json:"page,omitempty"`
+	Code           int       `json:"code"`
+	Msg            string    `json:"msg"`
+	Data           []T       `json:"data"`
+	Total          int64     `json:"total"`
+	TotalOmitted   bool      `json:"-"` // 未统计总数（with_total=false、游标分页），响应中省略 total
+	TotalEstimated bool      `json:"total_estimated,omitempty"`
+	HasMore        *bool     `json:"has_more,omitempty"`
+	Page           *PageInfo `json:"page,omitempty"`
+	NextCursor     string    `json:"next_cursor,omitempty"`
+	PrevCursor     string    `json:"prev_cursor,omitempty"`
+}
+
+// pageResponseJSON 与 PageResponse 字段相同、没有 MarshalJSON 方法，供其序列化
+type pageResponseJSON[T any] PageResponse[T]
+
+// MarshalJSON 未统计总数时省略 total
+func (r PageResponse[T]) MarshalJSON() ([]byte, error) {
+	if !r.TotalOmitted {
+		return json.Marshal(pageResponseJSON[T](r))
+	}
+	return json.Marshal(struct {
+		pageResponseJSON[T]
+		Total *int64 `json:"total,omitempty"`
+	}{pageResponseJSON: pageResponseJSON[T](r)})
 }
 
 type PageInfo struct {
@@ -14,9 +35,11 @@ type PageInfo struct {
 }
 
 type Response[T any] struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
-	Data T      `json:"data"`
+	Code      int          `json:"code"`
+	Msg       string       `json:"msg"`
+	Data      T            `json:"data"`
+	ErrorCode string       `json:"error_code,omitempty"` // 错误码，如 not_found、conflict，见 TranslateError
+	Errors    []FieldError `json:"errors,omitempty"`     // 请求校验失败时的字段错误
 }
 
 func Success[T any](data T) Response[T] {
@@ -45,6 +68,24 @@ func SuccessWithPage[T any](data []T, page *Page, total int64) PageResponse[T] {
 	return resp
 }
 
+// SuccessWithResult 根据分页查询结果构造响应（包含游标）
+func SuccessWithResult[T any](result *PageResult[T], page *Page) PageResponse[T] {
+	resp := SuccessWithPage(result.Data, page, result.Total)
+	switch result.TotalMode {
+	case TotalNone:
+		resp.TotalOmitted = true
+	case TotalEstimate:
+		resp.TotalEstimated = true
+	}
+	if page.IsValid() || page.IsCursor() {
+		resp.HasMore = &result.HasMore
+	}
+	resp.NextCursor = result.NextCursor
+	resp.PrevCursor = result.PrevCursor
+	return resp
+}
+
+// Error 500 错误响应，按错误类型响应请使用 RespondError
 func Error(msg string) Response[interface{}] {
 	return Response[interface{}]{
 		Code: 500,
@@ -52,3 +93,12 @@ func Error(msg string) Response[interface{}] {
 		Data: nil,
 	}
 }
+
+// ErrorWithCode 指定 code 的错误响应，通用处理器中 code 与 HTTP 状态码一致
+func ErrorWithCode(code int, msg string) Response[interface{}] {
+	return Response[interface{}]{
+		Code: code,
+		Msg:  msg,
+		Data: nil,
+	}
+}
@@ -0,0 +1,311 @@
+package dbkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/schema"
+)
+
+// ResponseWriter 通用处理器的响应格式，通过 WithResponseWriter 按处理器或资源选择：
+// EnvelopeWriter（默认，{code,msg,data}）、BareWriter（裸数据 + 分页响应头）、JSONAPIWriter（JSON:API）
+type ResponseWriter interface {
+	WriteResource(c *gin.Context, status int, resource interface{}) // 单条记录
+	WritePage(c *gin.Context, page *PageData)                       // 记录列表
+	WriteData(c *gin.Context, status int, data interface{})         // 非记录数据，如 affected、统计结果
+	WriteError(c *gin.Context, err *APIError)
+}
+
+// PageData 分页查询结果，Items 为当前页的记录
+type PageData struct {
+	Items []interface{}
+	Page  *Page
+	PageMeta
+}
+
+// WithResponseWriter 设置响应格式
+func WithResponseWriter(w ResponseWriter) Option {
+	return func(o *options) {
+		o.writer = w
+	}
+}
+
+// writePage 将分页查询结果交给 ResponseWriter，每条记录按 m 取舍字段
+func writePage[T any](c *gin.Context, w ResponseWriter, result *PageResult[T], page *Page, m fieldMask) {
+	items := make([]interface{}, len(result.Data))
+	for i := range result.Data {
+		items[i] = m.apply(result.Data[i])
+	}
+	w.WritePage(c, &PageData{Items: items, Page: page, PageMeta: result.PageMeta})
+}
+
+// writeError 按 ResponseWriter 响应错误，500 错误的原始信息记录到 c.Errors
+func writeError(c *gin.Context, w ResponseWriter, err error) {
+	apiErr := TranslateError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		_ = c.Error(err)
+	}
+	w.WriteError(c, apiErr)
+}
+
+// ============ EnvelopeWriter ============
+
+// EnvelopeWriter 默认格式：{code,msg,data}，分页信息在响应体中
+type EnvelopeWriter struct{}
+
+func (EnvelopeWriter) WriteResource(c *gin.Context, status int, resource interface{}) {
+	c.JSON(status, Success(resource))
+}
+
+func (EnvelopeWriter) WritePage(c *gin.Context, page *PageData) {
+	result := &PageResult[interface{}]{Data: page.Items, PageMeta: page.PageMeta}
+	c.JSON(http.StatusOK, SuccessWithResult(result, page.Page))
+}
+
+func (EnvelopeWriter) WriteData(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, Success(data))
+}
+
+func (EnvelopeWriter) WriteError(c *gin.Context, err *APIError) {
+	resp := ErrorWithCode(err.Status, err.Message)
+	resp.ErrorCode = err.Code
+	resp.Errors = err.Errors
+	c.JSON(err.Status, resp)
+}
+
+// ============ BareWriter ============
+
+// BareWriter 响应体只有数据本身，列表为 JSON 数组，分页信息放在响应头：
+// X-Total-Count、X-Has-More、X-Next-Cursor 与 Link（GET 请求时包含 first/prev/next/last）
+type BareWriter struct{}
+
+func (BareWriter) WriteResource(c *gin.Context, status int, resource interface{}) {
+	c.JSON(status, resource)
+}
+
+func (BareWriter) WritePage(c *gin.Context, page *PageData) {
+	header := c.Writer.Header()
+	if page.TotalMode != TotalNone {
+		header.Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	}
+	if page.Page.IsValid() || page.Page.IsCursor() {
+		header.Set("X-Has-More", strconv.FormatBool(page.HasMore))
+	}
+	if page.NextCursor != "" {
+		header.Set("X-Next-Cursor", page.NextCursor)
+	}
+	if page.PrevCursor != "" {
+		header.Set("X-Prev-Cursor", page.PrevCursor)
+	}
+
+	if links := pageLinks(c, page); len(links) > 0 {
+		parts := make([]string, 0, len(links))
+		for _, rel := range linkRels {
+			if href, ok := links[rel]; ok {
+				parts = append(parts, fmt.Sprintf("<%s>; rel=%q", href, rel))
+			}
+		}
+		header.Set("Link", strings.Join(parts, ", "))
+	}
+
+	c.JSON(http.StatusOK, page.Items)
+}
+
+func (BareWriter) WriteData(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, data)
+}
+
+func (BareWriter) WriteError(c *gin.Context, err *APIError) {
+	body := gin.H{"error": err.Code, "message": err.Message}
+	if len(err.Errors) > 0 {
+		body["errors"] = err.Errors
+	}
+	c.JSON(err.Status, body)
+}
+
+// linkRels Link 响应头中关系的输出顺序
+var linkRels = []string{"first", "prev", "next", "last"}
+
+// pageLinks 分页链接，按请求的查询串改写 page/cursor 参数，只对 GET 请求生成
+func pageLinks(c *gin.Context, page *PageData) map[string]string {
+	if c.Request.Method != http.MethodGet || page.Page == nil {
+		return nil
+	}
+
+	link := func(set map[string]string) string {
+		u := *c.Request.URL
+		query := u.Query()
+		for key, value := range set {
+			query.Set(key, value)
+		}
+		u.RawQuery = query.Encode()
+		return u.RequestURI()
+	}
+
+	links := make(map[string]string)
+	size := strconv.Itoa(page.Page.PageSize)
+
+	if page.Page.IsCursor() {
+		if page.NextCursor != "" {
+			links["next"] = link(map[string]string{"cursor": page.NextCursor, "page_size": size})
+		}
+		if page.PrevCursor != "" {
+			links["prev"] = link(map[string]string{"cursor": page.PrevCursor, "page_size": size})
+		}
+		return links
+	}
+
+	if !page.Page.IsValid() {
+		return nil
+	}
+
+	num := page.Page.PageNum
+	pageLink := func(n int) string {
+		return link(map[string]string{"page": strconv.Itoa(n), "page_size": size})
+	}
+
+	links["first"] = pageLink(1)
+	if num > 1 {
+		links["prev"] = pageLink(num - 1)
+	}
+	if page.HasMore {
+		links["next"] = pageLink(num + 1)
+	}
+	if page.TotalMode == TotalExact {
+		last := int((page.Total + int64(page.Page.PageSize) - 1) / int64(page.Page.PageSize))
+		if last < 1 {
+			last = 1
+		}
+		links["last"] = pageLink(last)
+	}
+	return links
+}
+
+// ============ JSONAPIWriter ============
+
+// jsonAPIContentType JSON:API 规定的媒体类型
+const jsonAPIContentType = "application/vnd.api+json"
+
+// JSONAPIWriter JSON:API 格式（https://jsonapi.org）：记录为 {type,id,attributes} 资源对象，
+// 分页信息在 meta 与 links 中，非记录数据放在 meta 中。
+// Type 为资源类型，未设置时按记录的类型名推断（如 User -> users）。
+type JSONAPIWriter struct {
+	Type string
+}
+
+func (w JSONAPIWriter) WriteResource(c *gin.Context, status int, resource interface{}) {
+	c.Render(status, jsonAPIRender{gin.H{"data": w.resourceObject(resource)}})
+}
+
+func (w JSONAPIWriter) WritePage(c *gin.Context, page *PageData) {
+	data := make([]gin.H, len(page.Items))
+	for i, item := range page.Items {
+		data[i] = w.resourceObject(item)
+	}
+
+	meta := gin.H{}
+	if page.TotalMode != TotalNone {
+		meta["total"] = page.Total
+		if page.TotalMode == TotalEstimate {
+			meta["total_estimated"] = true
+		}
+	}
+	if page.Page.IsValid() {
+		meta["page"] = PageInfo{PageNum: page.Page.PageNum, PageSize: page.Page.PageSize}
+	}
+	if page.Page.IsValid() || page.Page.IsCursor() {
+		meta["has_more"] = page.HasMore
+	}
+	if page.NextCursor != "" {
+		meta["next_cursor"] = page.NextCursor
+	}
+	if page.PrevCursor != "" {
+		meta["prev_cursor"] = page.PrevCursor
+	}
+
+	doc := gin.H{"data": data, "meta": meta}
+	if links := pageLinks(c, page); len(links) > 0 {
+		links["self"] = c.Request.URL.RequestURI()
+		doc["links"] = links
+	}
+	c.Render(http.StatusOK, jsonAPIRender{doc})
+}
+
+func (JSONAPIWriter) WriteData(c *gin.Context, status int, data interface{}) {
+	c.Render(status, jsonAPIRender{gin.H{"meta": data}})
+}
+
+func (JSONAPIWriter) WriteError(c *gin.Context, err *APIError) {
+	status := strconv.Itoa(err.Status)
+	var errs []gin.H
+	for _, fe := range err.Errors {
+		errs = append(errs, gin.H{
+			"status": status,
+			"code":   err.Code,
+			"title":  fe.Rule,
+			"detail": fe.Message,
+			"source": gin.H{"pointer": "/" + strings.ReplaceAll(fe.Field, ".", "/")},
+		})
+	}
+	if len(errs) == 0 {
+		errs = append(errs, gin.H{"status": status, "code": err.Code, "title": err.Message})
+	}
+	c.Render(err.Status, jsonAPIRender{gin.H{"errors": errs}})
+}
+
+// resourceObject 将记录转换为资源对象：json 中的 id 作为资源 id，其余字段作为 attributes
+func (w JSONAPIWriter) resourceObject(record interface{}) gin.H {
+	attributes := map[string]interface{}{}
+	if raw, err := json.Marshal(record); err == nil {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber() // 保持数值主键原样，避免转为浮点数
+		_ = decoder.Decode(&attributes)
+	}
+
+	obj := gin.H{"type": w.resourceType(record)}
+	if id, ok := attributes["id"]; ok {
+		obj["id"] = fmt.Sprint(id)
+		delete(attributes, "id")
+	}
+	obj["attributes"] = attributes
+	return obj
+}
+
+func (w JSONAPIWriter) resourceType(record interface{}) string {
+	if w.Type != "" {
+		return w.Type
+	}
+	if m, ok := record.(maskedRecord); ok {
+		record = m.record
+	}
+	t := reflect.TypeOf(record)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return schema.NamingStrategy{}.TableName(t.Name())
+}
+
+// jsonAPIRender 以 JSON:API 媒体类型输出 JSON
+type jsonAPIRender struct {
+	doc interface{}
+}
+
+func (r jsonAPIRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(r.doc)
+}
+
+func (r jsonAPIRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", jsonAPIContentType)
+}
@@ -0,0 +1,51 @@
+package dbkit
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// parseSchema 解析模型的 GORM schema（带缓存）
+func parseSchema(db *gorm.DB, model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// lookupField 按 json 名、列名或字段名查找客户端可引用的数据库字段，json:"-" 的字段视为不存在
+func lookupField(sch *schema.Schema, name string) *schema.Field {
+	if field := schemaField(sch, name); field != nil && jsonName(field) != "-" {
+		return field
+	}
+	return nil
+}
+
+// schemaField 按 json 名、列名或字段名查找数据库字段，包括 json:"-" 的字段；
+// 只用于服务端给出的名称（策略、版本字段）与写入
+func schemaField(sch *schema.Schema, name string) *schema.Field {
+	if name == "" || name == "-" {
+		return nil
+	}
+	for _, field := range sch.Fields {
+		if field.DBName != "" && jsonName(field) == name {
+			return field
+		}
+	}
+	if field := sch.LookUpField(name); field != nil && field.DBName != "" {
+		return field
+	}
+	return nil
+}
+
+// jsonName 字段的 json 名称，未设置时为字段名
+func jsonName(field *schema.Field) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
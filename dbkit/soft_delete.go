@@ -0,0 +1,140 @@
+package dbkit
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var ErrSoftDeleteUnsupported = errors.New("soft delete is not supported")
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// DeletedScopeRequest 可查询已软删除记录的请求：
+// include_deleted 同时返回已删除的记录，only_deleted 只返回已删除的记录。
+// 通用处理器中需要 Hooks.AllowDeleted 放行，否则返回 403。
+type DeletedScopeRequest interface {
+	GetDeletedScope() (includeDeleted, onlyDeleted bool)
+}
+
+func (r *BaseQueryRequest[F, O]) GetDeletedScope() (includeDeleted, onlyDeleted bool) {
+	return r.IncludeDeleted, r.OnlyDeleted
+}
+
+// requestsDeleted 请求是否要查询已删除的记录
+func requestsDeleted(req interface{}) bool {
+	r, ok := req.(DeletedScopeRequest)
+	if !ok {
+		return false
+	}
+	include, only := r.GetDeletedScope()
+	return include || only
+}
+
+// softDeleteField 模型的软删除字段（gorm.DeletedAt），没有时返回 nil
+func softDeleteField(sch *schema.Schema) *schema.Field {
+	if sch == nil {
+		return nil
+	}
+	for _, field := range sch.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			return field
+		}
+	}
+	return nil
+}
+
+// applyDeletedScope 按请求查询已删除的记录，模型没有软删除字段时 only_deleted 返回错误
+func (qb *QueryBuilder) applyDeletedScope(req QueryRequest) *QueryBuilder {
+	r, ok := req.(DeletedScopeRequest)
+	if !ok {
+		return qb
+	}
+
+	include, only := r.GetDeletedScope()
+	if !include && !only {
+		return qb
+	}
+
+	field := softDeleteField(qb.modelSchema())
+	if field == nil {
+		if only {
+			qb.db.AddError(fmt.Errorf("%w: only_deleted requires a deleted_at column", ErrSoftDeleteUnsupported))
+		}
+		return qb
+	}
+
+	qb.db = qb.db.Unscoped()
+	if only {
+		qb.db = qb.db.Where(qb.quote(field.DBName) + " IS NOT NULL")
+	}
+	return qb
+}
+
+// RestoreByID 恢复已软删除的记录，记录不存在或未被删除时返回 gorm.ErrRecordNotFound
+func RestoreByID[T any](db *gorm.DB, id interface{}) (*T, error) {
+	if auditEnabled(db) {
+		var out *T
+		scope := func(tx *gorm.DB) *gorm.DB { return idScope(id)(tx.Unscoped()) }
+		err := auditChanges[T](db, AuditRestore, scope, func(tx *gorm.DB) (err error) {
+			out, err = RestoreByID[T](tx, id)
+			return err
+		})
+		return out, err
+	}
+
+	var model T
+	qb := NewQueryBuilder(db.Unscoped().Model(&model))
+	if err := qb.whereID(id); err != nil {
+		return nil, err
+	}
+
+	field := softDeleteField(qb.modelSchema())
+	if field == nil {
+		return nil, fmt.Errorf("%w: %T has no deleted_at column", ErrSoftDeleteUnsupported, model)
+	}
+
+	res := qb.GetDB().Where(qb.quote(field.DBName)+" IS NOT NULL").Update(field.DBName, nil)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return GetByID[T](db, id)
+}
+
+// PurgeByID 永久删除记录（包括已软删除的记录），不存在时返回 gorm.ErrRecordNotFound
+func PurgeByID[T any](db *gorm.DB, id interface{}) error {
+	if auditEnabled(db) {
+		scope := func(tx *gorm.DB) *gorm.DB { return idScope(id)(tx.Unscoped()) }
+		return auditChanges[T](db, AuditPurge, scope, func(tx *gorm.DB) error {
+			return PurgeByID[T](tx, id)
+		})
+	}
+
+	var model T
+	qb := NewQueryBuilder(db.Unscoped().Model(&model))
+	if err := qb.whereID(id); err != nil {
+		return err
+	}
+
+	res := qb.GetDB().Delete(&model)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// supportsSoftDelete 实体是否有软删除字段
+func supportsSoftDelete[T any](db *gorm.DB) bool {
+	sch, err := parseSchema(db, new(T))
+	return err == nil && softDeleteField(sch) != nil
+}
@@ -2,107 +2,125 @@ package dbkit
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+
 	"gorm.io/gorm"
 )
 
 // QueryStats 查询统计信息
 type QueryStats struct {
-	Count int64                  `json:"count"`
-	Sum   map[string]float64     `json:"sum,omitempty"`
-	Avg   map[string]float64     `json:"avg,omitempty"`
-	Min   map[string]interface{} `json:"min,omitempty"`
-	Max   map[string]interface{} `json:"max,omitempty"`
+	Group         map[string]interface{} `json:"group,omitempty"` // 分组统计时为该组的分组字段值
+	Count         int64                  `json:"count"`
+	CountDistinct map[string]int64       `json:"count_distinct,omitempty"`
+	Sum           map[string]float64     `json:"sum,omitempty"`
+	Avg           map[string]float64     `json:"avg,omitempty"`
+	Min           map[string]interface{} `json:"min,omitempty"`
+	Max           map[string]interface{} `json:"max,omitempty"`
+	Stddev        map[string]float64     `json:"stddev,omitempty"`   // 总体标准差
+	Variance      map[string]float64     `json:"variance,omitempty"` // 总体方差
+
+	Percentiles map[string]map[string]float64 `json:"percentiles,omitempty"` // 字段 -> p50/p90... -> 值
+	Histograms  map[string][]HistogramBucket  `json:"histograms,omitempty"`  // 字段 -> 数值分桶
+
+	Groups []QueryStats `json:"groups,omitempty"` // 按 group_by 分组的统计结果
+
+	Key       string       `json:"key,omitempty"`       // 时间分桶统计时为桶的起始时间
+	Histogram []QueryStats `json:"histogram,omitempty"` // 按 date_histogram 分桶的统计结果
 }
 
 // StatsConfig 统计配置
 type StatsConfig struct {
-	SumFields []string `json:"sum_fields"` // 需要求和的字段
-	AvgFields []string `json:"avg_fields"` // 需要求平均的字段
-	MinFields []string `json:"min_fields"` // 需要求最小值的字段
-	MaxFields []string `json:"max_fields"` // 需要求最大值的字段
+	SumFields      []string `json:"sum_fields"`      // 需要求和的字段
+	AvgFields      []string `json:"avg_fields"`      // 需要求平均的字段
+	MinFields      []string `json:"min_fields"`      // 需要求最小值的字段
+	MaxFields      []string `json:"max_fields"`      // 需要求最大值的字段
+	DistinctFields []string `json:"distinct_fields"` // 需要统计不同值个数的字段
+	StddevFields   []string `json:"stddev_fields"`   // 需要求标准差的字段
+	VarianceFields []string `json:"variance_fields"` // 需要求方差的字段
+
+	PercentileFields []string           `json:"percentile_fields"` // 需要求分位数的字段，仅对整体统计生效
+	Percentiles      []float64          `json:"percentiles"`       // 分位点（0-100），默认 50、90、99
+	Histograms       []NumericHistogram `json:"histograms"`        // 数值字段按固定宽度分桶，仅对整体统计生效
+
+	GroupBy    []string     `json:"group_by"`    // 分组字段，非空时额外返回每组的统计
+	GroupOrder []GroupOrder `json:"group_order"` // 分组排序，field 可为分组字段、count、sum_<字段>、avg_<字段> 等
+	GroupLimit int          `json:"group_limit"` // 最多返回的分组数
+
+	DateHistogram *DateHistogram `json:"date_histogram"` // 按时间分桶统计，不能与 group_by 同时使用
 }
 
+// defaultPercentiles 未指定分位点时使用的默认值
+var defaultPercentiles = []float64{50, 90, 99}
+
 // Stats 执行统计查询
 func Stats[T any](db *gorm.DB, req QueryRequest, config StatsConfig) (*QueryStats, error) {
 	var model T
-	stats := &QueryStats{
-		Sum: make(map[string]float64),
-		Avg: make(map[string]float64),
-		Min: make(map[string]interface{}),
-		Max: make(map[string]interface{}),
-	}
-
-	qb := NewQueryBuilder(db.Model(&model))
-	qb.ApplyFilters(req.GetFilters())
-
-	// 获取总数
-	if err := qb.db.Count(&stats.Count).Error; err != nil {
-		return nil, err
-	}
-
-	// 构建 SELECT 语句
-	var selectFields []string
 
-	for _, field := range config.SumFields {
-		selectFields = append(selectFields, fmt.Sprintf("SUM(%s) as sum_%s", field, field))
+	if len(config.GroupBy) > 0 && config.DateHistogram != nil {
+		return nil, fmt.Errorf("%w: group_by and date_histogram cannot be used together", ErrInvalidAggregate)
 	}
 
-	for _, field := range config.AvgFields {
-		selectFields = append(selectFields, fmt.Sprintf("AVG(%s) as avg_%s", field, field))
+	// 每个统计查询都从带过滤条件的新查询开始
+	filtered := func() *QueryBuilder {
+		return NewQueryBuilder(db.Model(&model)).applyRequestFilters(req)
 	}
 
-	for _, field := range config.MinFields {
-		selectFields = append(selectFields, fmt.Sprintf("MIN(%s) as min_%s", field, field))
+	// 总数与各字段统计在一条查询中完成，字段需能在模型 schema 中解析，并按方言加引号
+	qb := filtered()
+	aggregates, fields, err := qb.statsAggregates(config)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, field := range config.MaxFields {
-		selectFields = append(selectFields, fmt.Sprintf("MAX(%s) as max_%s", field, field))
+	selects, columns, err := qb.selectAggregates(aggregates)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(selectFields) == 0 {
-		return stats, nil
+	qb.db = qb.db.Select(selects)
+	rows, err := scanGroupRows(qb.GetDB(), columns)
+	if err != nil {
+		return nil, err
 	}
 
-	// 执行聚合查询
-	var result map[string]interface{}
-	qb2 := NewQueryBuilder(db.Model(&model))
-	qb2.ApplyFilters(req.GetFilters())
-
-	// 直接在 db 上执行 Select 和 Find
-	if err := qb2.db.Select(selectFields).Limit(1).Find(&result).Error; err != nil {
-		return nil, err
+	stats := &QueryStats{}
+	if len(rows) > 0 {
+		*stats = statsFromRow(rows[0], fields)
 	}
 
-	// 解析结果
-	for _, field := range config.SumFields {
-		key := fmt.Sprintf("sum_%s", field)
-		if val, ok := result[key]; ok && val != nil {
-			if floatVal, ok := val.(float64); ok {
-				stats.Sum[field] = floatVal
-			}
+	if len(config.GroupBy) > 0 {
+		groups, err := filtered().groupedStats(config)
+		if err != nil {
+			return nil, err
 		}
+		stats.Groups = groups
 	}
 
-	for _, field := range config.AvgFields {
-		key := fmt.Sprintf("avg_%s", field)
-		if val, ok := result[key]; ok && val != nil {
-			if floatVal, ok := val.(float64); ok {
-				stats.Avg[field] = floatVal
-			}
+	if config.DateHistogram != nil {
+		buckets, err := filtered().dateHistogram(config)
+		if err != nil {
+			return nil, err
 		}
+		stats.Histogram = buckets
 	}
 
-	for _, field := range config.MinFields {
-		key := fmt.Sprintf("min_%s", field)
-		if val, ok := result[key]; ok && val != nil {
-			stats.Min[field] = val
+	if len(config.PercentileFields) > 0 {
+		percentiles, err := percentileStats(filtered, config)
+		if err != nil {
+			return nil, err
 		}
+		stats.Percentiles = percentiles
 	}
 
-	for _, field := range config.MaxFields {
-		key := fmt.Sprintf("max_%s", field)
-		if val, ok := result[key]; ok && val != nil {
-			stats.Max[field] = val
+	if len(config.Histograms) > 0 {
+		stats.Histograms = make(map[string][]HistogramBucket, len(config.Histograms))
+		for _, h := range config.Histograms {
+			buckets, err := filtered().numericHistogram(h)
+			if err != nil {
+				return nil, err
+			}
+			stats.Histograms[h.Field] = buckets
 		}
 	}
 
@@ -116,3 +134,204 @@ func SimpleStats[T any](db *gorm.DB, req QueryRequest, sumFields []string) (*Que
 		AvgFields: sumFields,
 	})
 }
+
+// statsField 统计字段与其在结果中的别名
+type statsField struct {
+	fn, field, alias string
+}
+
+// statsAggregates 生成 count 以及各统计字段的聚合表达式
+func (qb *QueryBuilder) statsAggregates(config StatsConfig) ([]Aggregate, []statsField, error) {
+	aggregates := []Aggregate{{Func: "count", Alias: "count"}}
+	var fields []statsField
+
+	for _, agg := range []struct {
+		fn     string
+		fields []string
+	}{
+		{"count_distinct", config.DistinctFields},
+		{"sum", config.SumFields},
+		{"avg", config.AvgFields},
+		{"min", config.MinFields},
+		{"max", config.MaxFields},
+		{"stddev", config.StddevFields},
+		{"variance", config.VarianceFields},
+	} {
+		for _, field := range agg.fields {
+			column, err := qb.resolveColumn(field)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// 标准差由方差开方得到，SQLite 也可使用
+			fn := agg.fn
+			if fn == "stddev" {
+				fn = "variance"
+			}
+
+			alias := agg.fn + "_" + column
+			aggregates = append(aggregates, Aggregate{Func: fn, Column: column, Alias: alias})
+			fields = append(fields, statsField{fn: agg.fn, field: field, alias: alias})
+		}
+	}
+
+	return aggregates, fields, nil
+}
+
+// selectAggregates 生成聚合的 SELECT 片段与结果列
+func (qb *QueryBuilder) selectAggregates(aggregates []Aggregate) ([]string, []groupColumn, error) {
+	selects := make([]string, 0, len(aggregates))
+	columns := make([]groupColumn, 0, len(aggregates))
+	for _, agg := range aggregates {
+		expr, err := qb.aggregateExpr(agg)
+		if err != nil {
+			return nil, nil, err
+		}
+		selects = append(selects, expr+" AS "+qb.quote(agg.Alias))
+		columns = append(columns, groupColumn{alias: agg.Alias, typ: qb.aggregateType(agg)})
+	}
+	return selects, columns, nil
+}
+
+// statsFromRow 将一行聚合结果转换为 QueryStats，数值统一按 float64 解析（兼容 DECIMAL）
+func statsFromRow(row map[string]interface{}, fields []statsField) QueryStats {
+	stats := QueryStats{
+		CountDistinct: make(map[string]int64),
+		Sum:           make(map[string]float64),
+		Avg:           make(map[string]float64),
+		Min:           make(map[string]interface{}),
+		Max:           make(map[string]interface{}),
+		Stddev:        make(map[string]float64),
+		Variance:      make(map[string]float64),
+	}
+	stats.Count, _ = toInt64(row["count"])
+
+	for _, f := range fields {
+		val := row[f.alias]
+		if val == nil {
+			continue
+		}
+		switch f.fn {
+		case "count_distinct":
+			if n, ok := toInt64(val); ok {
+				stats.CountDistinct[f.field] = n
+			}
+		case "sum":
+			if n, ok := toFloat64(val); ok {
+				stats.Sum[f.field] = n
+			}
+		case "avg":
+			if n, ok := toFloat64(val); ok {
+				stats.Avg[f.field] = n
+			}
+		case "min":
+			stats.Min[f.field] = val
+		case "max":
+			stats.Max[f.field] = val
+		case "stddev":
+			if n, ok := toFloat64(val); ok {
+				// 浮点误差可能使方差略小于 0
+				stats.Stddev[f.field] = math.Sqrt(math.Max(n, 0))
+			}
+		case "variance":
+			if n, ok := toFloat64(val); ok {
+				stats.Variance[f.field] = n
+			}
+		}
+	}
+	return stats
+}
+
+// groupedStats 按 config.GroupBy 分组统计，每组一个 QueryStats
+func (qb *QueryBuilder) groupedStats(config StatsConfig) ([]QueryStats, error) {
+	aggregates, fields, err := qb.statsAggregates(config)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := qb.applyGroupSpec(GroupSpec{
+		GroupBy:    config.GroupBy,
+		Aggregates: aggregates,
+		Orders:     config.GroupOrder,
+		Limit:      config.GroupLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := scanGroupRows(qb.GetDB(), columns)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]QueryStats, 0, len(rows))
+	for _, row := range rows {
+		group := statsFromRow(row, fields)
+		group.Group = make(map[string]interface{}, len(config.GroupBy))
+		for _, col := range columns[:len(config.GroupBy)] {
+			group.Group[col.alias] = row[col.alias]
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// percentileStats 按最近秩法计算分位数：先统计非空值个数，再按偏移取第 k 个值
+func percentileStats(filtered func() *QueryBuilder, config StatsConfig) (map[string]map[string]float64, error) {
+	points := config.Percentiles
+	if len(points) == 0 {
+		points = defaultPercentiles
+	}
+	for _, p := range points {
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("%w: percentile %v out of range", ErrInvalidAggregate, p)
+		}
+	}
+
+	result := make(map[string]map[string]float64, len(config.PercentileFields))
+	for _, field := range config.PercentileFields {
+		qb := filtered()
+		column, err := qb.quoteColumn(field)
+		if err != nil {
+			return nil, err
+		}
+
+		var n int64
+		if err := qb.db.Where(column + " IS NOT NULL").Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			continue
+		}
+
+		values := make(map[string]float64, len(points))
+		for _, p := range points {
+			k := int(math.Ceil(p/100*float64(n))) - 1
+			if k < 0 {
+				k = 0
+			}
+
+			qb := filtered()
+			qb.db = qb.db.Select(column + " AS " + qb.quote("value")).
+				Where(column + " IS NOT NULL").
+				Order(column).
+				Offset(k).
+				Limit(1)
+
+			rows, err := scanGroupRows(qb.GetDB(), []groupColumn{{alias: "value", typ: float64Type}})
+			if err != nil {
+				return nil, err
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			if v, ok := toFloat64(rows[0]["value"]); ok {
+				values["p"+strconv.FormatFloat(p, 'f', -1, 64)] = v
+			}
+		}
+		result[field] = values
+	}
+
+	return result, nil
+}
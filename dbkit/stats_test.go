@@ -0,0 +1,41 @@
+package dbkit
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStatsRejectHiddenColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		config StatsConfig
+	}{
+		{name: "min", config: StatsConfig{MinFields: []string{"password"}}},
+		{name: "max", config: StatsConfig{MaxFields: []string{"Password"}}},
+		{name: "percentile", config: StatsConfig{PercentileFields: []string{"password"}}},
+		{name: "numeric histogram", config: StatsConfig{Histograms: []NumericHistogram{{Field: "password", Interval: 10}}}},
+		{name: "date histogram", config: StatsConfig{DateHistogram: &DateHistogram{Field: "password", Interval: "day"}}},
+		{name: "group by", config: StatsConfig{GroupBy: []string{"password"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+			rec.result = func(string) ([]string, [][]driver.Value) {
+				return []string{"count"}, [][]driver.Value{{int64(0)}}
+			}
+
+			_, err := Stats[secretUser](db, &BaseQueryRequest[struct{}, struct{}]{}, tt.config)
+			if !errors.Is(err, ErrInvalidColumn) {
+				t.Fatalf("err = %v, want ErrInvalidColumn", err)
+			}
+			for _, sql := range rec.SQL() {
+				if strings.Contains(sql, "password") {
+					t.Fatalf("hidden column was queried: %s", sql)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package dbkit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantSettingKey gorm.DB 上当前租户的设置项
+const tenantSettingKey = "dbkit:tenant"
+
+// errTenantNotEnabled 未注册租户回调时使用 ScopeTenant，租户条件不会生效
+var errTenantNotEnabled = errors.New("dbkit: ScopeTenant requires EnableTenant(db) at startup")
+
+// TenantResolver 从请求中解析当前租户，返回错误时中止请求
+type TenantResolver func(c *gin.Context) (interface{}, error)
+
+// WithTenant 为通用处理器开启租户隔离：带 tenant 标签字段的实体，所有读写都限定为 resolver 返回的租户，
+// 创建时自动填充租户字段，过滤条件与更新字段都无法越过该限制
+func WithTenant(resolver TenantResolver) Option {
+	return func(o *options) {
+		o.tenant = resolver
+	}
+}
+
+// TenantFromHeader 从请求头解析租户，如 TenantFromHeader("X-Tenant-ID")
+func TenantFromHeader(header string) TenantResolver {
+	return func(c *gin.Context) (interface{}, error) {
+		if tenant := c.GetHeader(header); tenant != "" {
+			return tenant, nil
+		}
+		return nil, Abort(http.StatusUnauthorized, "tenant is required")
+	}
+}
+
+// TenantFromContext 从 gin.Context 中解析租户，如认证中间件 c.Set("tenant_id", claims.TenantID) 后使用 TenantFromContext("tenant_id")
+func TenantFromContext(key string) TenantResolver {
+	return func(c *gin.Context) (interface{}, error) {
+		if tenant, ok := c.Get(key); ok && tenant != nil && tenant != "" {
+			return tenant, nil
+		}
+		return nil, Abort(http.StatusUnauthorized, "tenant is required")
+	}
+}
+
+// EnableTenant 注册租户隔离的 GORM 回调，须在启动时（处理请求之前）调用，之后才能使用 ScopeTenant。
+// 通用处理器使用 WithTenant 时在创建处理器时自动注册。
+//
+//	db = dbkit.EnableTenant(db)
+func EnableTenant(db *gorm.DB) *gorm.DB {
+	registerTenantCallbacks(db)
+	return db
+}
+
+// ScopeTenant 返回限定为租户 tenant 的 DB：对带 tenant 标签字段的模型，查询、统计、更新、删除追加租户条件，创建时填充租户字段。
+// 不注册回调，可在请求中调用；db 未经 EnableTenant 时后续语句返回错误，而不是忽略租户条件。
+//
+//	TenantID string `gorm:"type:varchar(32);index" json:"tenant_id" tenant:"true"`
+func ScopeTenant(db *gorm.DB, tenant interface{}) *gorm.DB {
+	tx := db.Set(tenantSettingKey, tenant).Session(&gorm.Session{})
+	if db.Callback().Query().Get("dbkit:tenant_query") == nil {
+		_ = tx.AddError(errTenantNotEnabled)
+	}
+	return tx
+}
+
+// tenantField 模型的租户字段（带 tenant 标签），没有时返回 nil
+func tenantField(sch *schema.Schema) *schema.Field {
+	if sch == nil {
+		return nil
+	}
+	for _, field := range sch.Fields {
+		if isTenantField(field) {
+			return field
+		}
+	}
+	return nil
+}
+
+func isTenantField(field *schema.Field) bool {
+	_, ok := field.Tag.Lookup("tenant")
+	return ok && field.DBName != ""
+}
+
+// tenantOf 语句所限定的租户及模型的租户字段，未限定或模型没有租户字段时 field 为 nil
+func tenantOf(db *gorm.DB) (tenant interface{}, field *schema.Field) {
+	tenant, ok := db.Get(tenantSettingKey)
+	if !ok || db.Statement.Schema == nil {
+		return nil, nil
+	}
+	return tenant, tenantField(db.Statement.Schema)
+}
+
+var tenantCallbacksMu sync.Mutex
+
+// registerTenantCallbacks 注册租户隔离的 GORM 回调，同一 DB 只注册一次
+func registerTenantCallbacks(db *gorm.DB) {
+	tenantCallbacksMu.Lock()
+	defer tenantCallbacksMu.Unlock()
+
+	cb := db.Callback()
+	if cb.Query().Get("dbkit:tenant_query") != nil {
+		return
+	}
+	_ = cb.Query().Before("gorm:query").Register("dbkit:tenant_query", tenantScopeCallback)
+	_ = cb.Row().Before("gorm:row").Register("dbkit:tenant_row", tenantScopeCallback)
+	_ = cb.Update().Before("gorm:update").Register("dbkit:tenant_update", tenantWriteCallback)
+	_ = cb.Delete().Before("gorm:delete").Register("dbkit:tenant_delete", tenantWriteCallback)
+	_ = cb.Create().Before("gorm:create").Register("dbkit:tenant_create", tenantCreateCallback)
+}
+
+// tenantScopeCallback 追加租户条件，已有条件整体加括号，避免其中的 OR 越过租户限制
+func tenantScopeCallback(db *gorm.DB) {
+	tenant, field := tenantOf(db)
+	if field == nil {
+		return
+	}
+
+	stmt := db.Statement
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			c.Expression = clause.Where{Exprs: []clause.Expression{clause.And(where.Exprs...)}}
+			stmt.Clauses["WHERE"] = c
+		}
+	}
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenant},
+	}})
+}
+
+// tenantWriteCallback 更新、删除时追加租户条件，并且不允许修改租户字段。
+// 语句本身没有条件时不追加，交给 GORM 返回 ErrMissingWhereClause
+func tenantWriteCallback(db *gorm.DB) {
+	_, field := tenantOf(db)
+	if field == nil || !hasWriteConditions(db) {
+		return
+	}
+	db.Statement.Omits = append(db.Statement.Omits, field.DBName)
+	tenantScopeCallback(db)
+}
+
+// hasWriteConditions 更新、删除语句是否带条件：WHERE 或模型上的主键值
+func hasWriteConditions(db *gorm.DB) bool {
+	stmt := db.Statement
+	if _, ok := stmt.Clauses["WHERE"]; ok || db.AllowGlobalUpdate {
+		return true
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return false
+	}
+
+	rv := reflect.Indirect(stmt.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Struct:
+		_, isZero := pk.ValueOf(stmt.Context, rv)
+		return !isZero
+	case reflect.Slice, reflect.Array:
+		return rv.Len() > 0
+	}
+	return false
+}
+
+// tenantCreateCallback 创建时填充租户字段，覆盖请求中的值
+func tenantCreateCallback(db *gorm.DB) {
+	tenant, field := tenantOf(db)
+	if field == nil {
+		return
+	}
+
+	stmt := db.Statement
+	set := func(rv reflect.Value) {
+		rv = reflect.Indirect(rv)
+		if rv.Kind() != reflect.Struct {
+			return
+		}
+		if err := field.Set(stmt.Context, rv, tenant); err != nil {
+			_ = db.AddError(fmt.Errorf("set tenant: %w", err))
+		}
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			set(stmt.ReflectValue.Index(i))
+		}
+	case reflect.Struct:
+		set(stmt.ReflectValue)
+	case reflect.Map:
+		if values, ok := stmt.Dest.(map[string]interface{}); ok {
+			values[field.DBName] = tenant
+		}
+	}
+}
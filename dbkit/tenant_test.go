@@ -0,0 +1,102 @@
+package dbkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type tenantFilters struct {
+	Name *string `json:"name" filter:"eq"`
+	Logic[tenantFilters]
+}
+
+type tenantRequest = BaseQueryRequest[tenantFilters, struct{}]
+
+func TestScopeTenantRequiresEnableTenant(t *testing.T) {
+	db, _ := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+
+	err := ScopeTenant(db, 7).Find(&[]testUser{}).Error
+	if !errors.Is(err, errTenantNotEnabled) {
+		t.Fatalf("want errTenantNotEnabled, got %v", err)
+	}
+}
+
+func TestTenantScoping(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	scoped := ScopeTenant(EnableTenant(db), 7)
+
+	a, b := "a", "b"
+	req := &tenantRequest{
+		Page:    &Page{PageNum: 1, PageSize: 10},
+		Filters: tenantFilters{Logic: Logic[tenantFilters]{Or: []tenantFilters{{Name: &a}, {Name: &b}}}},
+	}
+	if _, err := QueryPage[testUser](scoped, req); err != nil {
+		t.Fatalf("QueryPage: %v", err)
+	}
+	if _, err := Stats[testUser](scoped, req, StatsConfig{SumFields: []string{"age"}}); err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+
+	// OR 条件整体加括号后与租户条件 AND
+	want := "WHERE (`name` = ? OR `name` = ?) AND `user`.`tenant_id` = ?"
+	for _, keyword := range []string{"count(*)", "LIMIT", "SUM("} {
+		if sql := rec.last(keyword); !strings.Contains(sql, want) || !strings.HasSuffix(sql, "a, b, 7") {
+			t.Errorf("%s query is not scoped to the tenant: %s", keyword, sql)
+		}
+	}
+}
+
+func TestEstimateCountFallsBackWhenScoped(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    func(db *gorm.DB) *gorm.DB
+		estimate bool
+	}{
+		{name: "unscoped", scope: func(db *gorm.DB) *gorm.DB { return db }, estimate: true},
+		{name: "tenant", scope: func(db *gorm.DB) *gorm.DB { return ScopeTenant(EnableTenant(db), 7) }},
+		{name: "policy", scope: func(db *gorm.DB) *gorm.DB {
+			return restrict[testUser](db, &Permission{Scope: DynamicFilter{{Field: "age", Op: "gte", Value: 18}}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+			req := &tenantRequest{Page: &Page{PageNum: 1, PageSize: 10}}
+			if _, err := QueryPage[testUser](tt.scope(db), req, WithTotalMode(TotalEstimate)); err != nil {
+				t.Fatalf("QueryPage: %v", err)
+			}
+
+			estimated := rec.last("information_schema") != ""
+			if estimated != tt.estimate {
+				t.Fatalf("estimated = %v, want %v: %v", estimated, tt.estimate, rec.SQL())
+			}
+			if !tt.estimate && rec.last("count(*)") == "" {
+				t.Fatalf("missing exact count: %v", rec.SQL())
+			}
+		})
+	}
+}
+
+func TestResetGuarded(t *testing.T) {
+	db := dryRunDB(t)
+
+	type guarded struct {
+		ID        uint           `gorm:"primaryKey" json:"id"`
+		Name      string         `json:"name"`
+		TenantID  string         `json:"tenant_id" tenant:"true"`
+		DeletedAt gorm.DeletedAt `json:"deleted_at"`
+	}
+
+	entity := guarded{Name: "a", TenantID: "other", DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+	if err := resetGuarded(db, &entity); err != nil {
+		t.Fatalf("resetGuarded: %v", err)
+	}
+	if entity.Name != "a" || entity.TenantID != "" || entity.DeletedAt.Valid {
+		t.Fatalf("unexpected entity after reset: %+v", entity)
+	}
+}
@@ -0,0 +1,120 @@
+package dbkit
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// errNoUpdates 更新请求中没有任何要更新的字段
+var errNoUpdates = &ValidationError{Errors: []FieldError{{
+	Field: "updates", Rule: "required", Message: "no fields to update",
+}}}
+
+// UpdatesOf 将类型化的更新结构体转换为更新字段集合，只收集非 nil 的指针字段：
+//
+//	type UserUpdates struct {
+//		Name *string `json:"name" binding:"omitempty,min=1"` // binding 标签在绑定请求时校验
+//		Age  *int    `json:"age"`
+//		Note *string `json:"note" update:"-"`                 // 不参与更新
+//	}
+//
+// 键为 column 标签，未设置时为 json 名；u 为 map[string]interface{} 时原样返回。
+// 实体上的主键、update:"-" 字段以及 GORM 只读字段在解析列名时拒绝更新。
+func UpdatesOf(u interface{}) (map[string]interface{}, error) {
+	if m, ok := u.(map[string]interface{}); ok {
+		return m, nil
+	}
+
+	v := reflect.ValueOf(u)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return map[string]interface{}{}, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: updates must be a struct, got %T", ErrInvalidColumn, u)
+	}
+
+	updates := make(map[string]interface{})
+	if err := collectUpdates(v, updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// collectUpdates 收集结构体（含嵌入结构体）中非 nil 的更新字段，
+// 非指针字段无法区分"未传"，返回错误
+func collectUpdates(v reflect.Value, updates map[string]interface{}) error {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Tag.Get("update") == "-" {
+			continue
+		}
+		// 未导出的嵌入结构体，其导出字段同样会被绑定
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if err := collectUpdates(v.Field(i), updates); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		default:
+			return fmt.Errorf("%w: update field %s.%s must be a pointer", ErrInvalidColumn, t, sf.Name)
+		}
+		if field.IsNil() {
+			continue
+		}
+
+		name := fieldColumn(sf)
+		if name == "" {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			updates[name] = field.Elem().Interface()
+		} else {
+			updates[name] = field.Interface()
+		}
+	}
+	return nil
+}
+
+// checkUpdatesType 校验更新结构体的字段均可区分"未传"，否则零值会覆盖数据库中的值。
+// 在创建处理器时调用，字段类型不合法时 panic。
+func checkUpdatesType(t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String {
+		return
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("dbkit: updates type %s must be a struct of pointer fields", t))
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Tag.Get("update") == "-" {
+			continue
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			checkUpdatesType(sf.Type)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		switch sf.Type.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		default:
+			panic(fmt.Sprintf("dbkit: update field %s.%s must be a pointer", t, sf.Name))
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package dbkit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUpdatesOf(t *testing.T) {
+	name, age := "alice", 0
+
+	type base struct {
+		Name *string `json:"name"`
+	}
+	type updates struct {
+		base
+		Age  *int    `json:"age"`
+		Note *string `json:"note" update:"-"`
+		Tags []int   `json:"tags" column:"tag_ids"`
+	}
+
+	got, err := UpdatesOf(&updates{base: base{Name: &name}, Age: &age, Note: &name})
+	if err != nil {
+		t.Fatalf("UpdatesOf: %v", err)
+	}
+	want := map[string]interface{}{"name": "alice", "age": 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("UpdatesOf = %v, want %v", got, want)
+	}
+
+	if got, err := UpdatesOf((*updates)(nil)); err != nil || len(got) != 0 {
+		t.Fatalf("UpdatesOf(nil) = %v, %v", got, err)
+	}
+
+	m := map[string]interface{}{"age": 1}
+	if got, err := UpdatesOf(m); err != nil || !reflect.DeepEqual(got, m) {
+		t.Fatalf("UpdatesOf(map) = %v, %v", got, err)
+	}
+}
+
+func TestUpdatesOfRejectsNonPointerFields(t *testing.T) {
+	type updates struct {
+		Name *string `json:"name"`
+		Age  int     `json:"age"`
+	}
+
+	if _, err := UpdatesOf(updates{}); !errors.Is(err, ErrInvalidColumn) {
+		t.Fatalf("want ErrInvalidColumn, got %v", err)
+	}
+	if _, err := UpdatesOf(42); !errors.Is(err, ErrInvalidColumn) {
+		t.Fatalf("want ErrInvalidColumn, got %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package dbkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrValidation     = errors.New("validation failed")
+)
+
+// defaultMaxPageSize 默认的每页条数上限，可通过 WithMaxPageSize 调整
+const defaultMaxPageSize = 1000
+
+// FieldError 单个字段的校验错误，Field 为请求体中的 json 路径，如 updates.age、items[1].name
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError 请求校验失败，响应中通过 errors 返回各字段的错误
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		messages[i] = fe.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// Validate 按 binding 标签校验结构体（切片逐个元素校验），失败时返回 *ValidationError
+func Validate(obj interface{}) error {
+	if binding.Validator == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var fieldErrors []FieldError
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fieldErrors = append(fieldErrors, validateValue(v.Index(i), fmt.Sprintf("[%d]", i))...)
+		}
+	case reflect.Struct:
+		fieldErrors = validateValue(v, "")
+	}
+
+	if len(fieldErrors) > 0 {
+		return &ValidationError{Errors: fieldErrors}
+	}
+	return nil
+}
+
+// validateValue 校验单个结构体，字段路径加上 prefix
+func validateValue(v reflect.Value, prefix string) []FieldError {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := binding.Validator.ValidateStruct(v.Addr().Interface())
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []FieldError{{Field: prefix, Rule: "invalid", Message: err.Error()}}
+	}
+
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		field := joinPath(prefix, jsonPath(v.Type(), fe.StructNamespace()))
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Message: ruleMessage(field, fe),
+		})
+	}
+	return fieldErrors
+}
+
+// jsonPath 将校验器的结构体命名空间（如 UserUpdates.Age、Items[1].Name）转换为 json 路径
+func jsonPath(root reflect.Type, namespace string) string {
+	// 具名类型以类型名开头，泛型类型名中可能含有 "."
+	if root.Name() != "" {
+		namespace = strings.TrimPrefix(namespace, root.Name()+".")
+	}
+	segments := strings.Split(namespace, ".")
+
+	var path []string
+	t := root
+	for _, segment := range segments {
+		name, index := segment, ""
+		if i := strings.IndexByte(segment, '['); i >= 0 {
+			name, index = segment[:i], segment[i:]
+		}
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		sf, ok := t.FieldByName(name)
+		if !ok {
+			path = append(path, segment)
+			continue
+		}
+
+		t = sf.Type
+		if index != "" {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+
+		// 嵌入结构体不出现在 json 路径中
+		if sf.Anonymous && strings.Split(sf.Tag.Get("json"), ",")[0] == "" {
+			continue
+		}
+		path = append(path, jsonFieldName(sf)+index)
+	}
+	return strings.Join(path, ".")
+}
+
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" {
+		return prefix
+	}
+	return prefix + "." + path
+}
+
+// ruleMessage 常用校验规则的错误信息
+func ruleMessage(field string, fe validator.FieldError) string {
+	param := fe.Param()
+	isString := fe.Kind() == reflect.String
+	isList := fe.Kind() == reflect.Slice || fe.Kind() == reflect.Array || fe.Kind() == reflect.Map
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min", "gte":
+		if isString {
+			return fmt.Sprintf("%s must be at least %s characters", field, param)
+		}
+		if isList {
+			return fmt.Sprintf("%s must contain at least %s items", field, param)
+		}
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, param)
+	case "max", "lte":
+		if isString {
+			return fmt.Sprintf("%s must be at most %s characters", field, param)
+		}
+		if isList {
+			return fmt.Sprintf("%s must contain at most %s items", field, param)
+		}
+		return fmt.Sprintf("%s must be less than or equal to %s", field, param)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, param)
+	case "len":
+		return fmt.Sprintf("%s must have length %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", field, param)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	}
+	if param != "" {
+		return fmt.Sprintf("%s failed on rule %s=%s", field, fe.Tag(), param)
+	}
+	return fmt.Sprintf("%s failed on rule %s", field, fe.Tag())
+}
+
+// BindJSON 解析请求体并按 binding 标签校验。
+// 类型不匹配与校验失败返回 *ValidationError，其余解析错误包装为 ErrInvalidRequest。
+func BindJSON(c *gin.Context, obj interface{}) error {
+	if c.Request == nil || c.Request.Body == nil {
+		return fmt.Errorf("%w: empty body", ErrInvalidRequest)
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	if binding.EnableDecoderUseNumber {
+		decoder.UseNumber()
+	}
+	if binding.EnableDecoderDisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(obj); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return &ValidationError{Errors: []FieldError{{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Message: fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type),
+			}}}
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	return Validate(obj)
+}
+
+// validateQuery 校验查询请求的分页参数与排序方向
+func validateQuery(req QueryRequest, o *options) error {
+	var fieldErrors []FieldError
+
+	if page, ok := req.GetPage().(*Page); ok && page != nil {
+		fieldErrors = append(fieldErrors, validatePage(page, o.maxPageSize)...)
+	}
+	fieldErrors = append(fieldErrors, validateOrders(req.GetOrders())...)
+
+	if len(fieldErrors) > 0 {
+		return &ValidationError{Errors: fieldErrors}
+	}
+	return nil
+}
+
+// validatePage 页码分页要求 page_num >= 1，page_size 在 1 到 maxPageSize 之间（maxPageSize <= 0 时不限制）。
+// 空的 {"page":{}} 与不传 page 相同，不分页
+func validatePage(page *Page, maxPageSize int) []FieldError {
+	if page.Cursor == nil && page.PageNum == 0 && page.PageSize == 0 {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+
+	if page.Cursor == nil && page.PageNum < 1 {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field: "page.page_num", Rule: "min", Message: "page.page_num must be greater than or equal to 1",
+		})
+	}
+	if page.PageSize < 1 {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field: "page.page_size", Rule: "min", Message: "page.page_size must be greater than or equal to 1",
+		})
+	}
+	if maxPageSize > 0 && page.PageSize > maxPageSize {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field: "page.page_size", Rule: "max", Message: fmt.Sprintf("page.page_size must be less than or equal to %d", maxPageSize),
+		})
+	}
+	return fieldErrors
+}
+
+// validateOrders 排序字段的值只能为 asc 或 desc（不区分大小写），不排序时省略该字段而不是传空串
+func validateOrders(orders interface{}) []FieldError {
+	v := reflect.ValueOf(orders)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		sf := t.Field(i)
+		field := v.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.String {
+			continue
+		}
+
+		direction := strings.ToLower(strings.TrimSpace(field.String()))
+		if direction != "asc" && direction != "desc" {
+			name := "orders." + jsonFieldName(sf)
+			fieldErrors = append(fieldErrors, FieldError{
+				Field: name, Rule: "oneof", Message: name + " must be one of [asc desc]",
+			})
+		}
+	}
+	return fieldErrors
+}
@@ -0,0 +1,63 @@
+package dbkit
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateQuery(t *testing.T) {
+	empty, asc := "", "ASC"
+	o := newOptions(nil)
+
+	tests := []struct {
+		name  string
+		req   *cursorRequest
+		field string
+	}{
+		{name: "empty page", req: &cursorRequest{Page: &Page{}}},
+		{name: "no page", req: &cursorRequest{}},
+		{name: "page size only", req: &cursorRequest{Page: &Page{PageSize: 10}}, field: "page.page_num"},
+		{name: "negative page", req: &cursorRequest{Page: &Page{PageNum: -1, PageSize: 10}}, field: "page.page_num"},
+		{name: "cursor without size", req: &cursorRequest{Page: &Page{Cursor: &empty}}, field: "page.page_size"},
+		{name: "order", req: &cursorRequest{Orders: cursorTestOrders{Age: &asc}}},
+		{name: "empty order", req: &cursorRequest{Orders: cursorTestOrders{Age: &empty}}, field: "orders.age"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateQuery(tt.req, o)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			verr, ok := err.(*ValidationError)
+			if !ok || len(verr.Errors) == 0 || verr.Errors[0].Field != tt.field {
+				t.Fatalf("err = %v, want a field error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestQueryHandlerEmptyPageAndOrder(t *testing.T) {
+	db, rec := recordDB(t, "user:pass@tcp(127.0.0.1:1)/test")
+	rec.result = func(string) ([]string, [][]driver.Value) {
+		return []string{"id"}, [][]driver.Value{{int64(1)}}
+	}
+	handler := GenericQueryHandler[testUser, struct{}, cursorTestOrders](db)
+
+	if w := serve("/query", http.MethodPost, "/query", `{"page":{}}`, handler, nil); w.Code != http.StatusOK {
+		t.Fatalf("empty page: status = %d: %s", w.Code, w.Body)
+	}
+	if sql := rec.last("SELECT"); strings.Contains(sql, "LIMIT") {
+		t.Fatalf("empty page must not paginate: %s", sql)
+	}
+
+	w := serve("/query", http.MethodPost, "/query", `{"orders":{"age":""}}`, handler, nil)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"rule":"oneof"`) {
+		t.Fatalf("empty order: status = %d: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,202 @@
+package dbkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrVersionConflict    = errors.New("version conflict")
+	ErrVersionRequired    = errors.New("version required")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// versionField 模型的乐观锁版本字段（带 version 标签的整数字段），没有时返回 nil：
+//
+//	Version int64 `gorm:"not null;default:1" json:"version" version:"true"`
+func versionField(sch *schema.Schema) *schema.Field {
+	if sch == nil {
+		return nil
+	}
+	for _, field := range sch.Fields {
+		if _, ok := field.Tag.Lookup("version"); ok && field.DBName != "" {
+			return field
+		}
+	}
+	return nil
+}
+
+// versionedUpdates 解析更新字段。模型有版本字段时：updates 中必须带版本号（否则返回 ErrVersionRequired），
+// 版本号作为条件（WHERE version = ?），并将版本号加一；conditioned 表示是否带了版本条件
+func (qb *QueryBuilder) versionedUpdates(updates map[string]interface{}) (columns map[string]interface{}, conditioned bool, err error) {
+	sch := qb.modelSchema()
+	field := versionField(sch)
+	if field == nil {
+		columns, err = qb.resolveUpdates(updates)
+		return columns, false, err
+	}
+
+	rest := make(map[string]interface{}, len(updates))
+	var expected interface{}
+	for name, value := range updates {
+		if schemaField(sch, name) == field {
+			expected, conditioned = value, value != nil
+			continue
+		}
+		rest[name] = value
+	}
+
+	columns, err = qb.resolveUpdates(rest)
+	if err != nil {
+		return nil, false, err
+	}
+	if !conditioned {
+		return nil, false, fmt.Errorf("%w: %s must be provided to update %s", ErrVersionRequired, jsonName(field), sch.Name)
+	}
+
+	column := qb.quote(field.DBName)
+	columns[field.DBName] = gorm.Expr(column + " + 1")
+	qb.db = qb.db.Where(column+" = ?", expected)
+	return columns, true, nil
+}
+
+// versionOf 记录的版本号，模型没有版本字段时返回 nil
+func versionOf(db *gorm.DB, record interface{}) interface{} {
+	sch, err := parseSchema(db, record)
+	if err != nil {
+		return nil
+	}
+	field := versionField(sch)
+	if field == nil {
+		return nil
+	}
+	value, _ := field.ValueOf(db.Statement.Context, reflect.Indirect(reflect.ValueOf(record)))
+	return value
+}
+
+// ETag 记录的实体标签：有版本字段时为 "v{版本号}"，否则为 JSON 内容的摘要
+func ETag(db *gorm.DB, record interface{}) string {
+	if version := versionOf(db, record); version != nil {
+		return fmt.Sprintf(`"v%v"`, version)
+	}
+
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches 判断 If-Match / If-None-Match 请求头是否匹配，忽略弱标签前缀 W/
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// setETag 设置 ETag 响应头
+func setETag(c *gin.Context, db *gorm.DB, record interface{}) {
+	if etag := ETag(db, record); etag != "" {
+		c.Header("ETag", etag)
+	}
+}
+
+// checkIfMatch 校验 If-Match 请求头：记录当前的 ETag 不匹配时返回 ErrPreconditionFailed。
+// 匹配且模型有版本字段时返回当前版本号，调用方将其作为更新条件，避免检查后被并发修改。
+func checkIfMatch[T any](c *gin.Context, db *gorm.DB, id interface{}) (version interface{}, err error) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return nil, nil
+	}
+
+	current, err := GetByID[T](db, id)
+	if err != nil {
+		return nil, err
+	}
+	if !etagMatches(header, ETag(db, current)) {
+		return nil, fmt.Errorf("%w: If-Match does not match the current version", ErrPreconditionFailed)
+	}
+	return versionOf(db, current), nil
+}
+
+// preconditionError 带 If-Match 的请求在更新时版本冲突，说明检查后记录已被修改
+func preconditionError(err error, ifMatch bool) error {
+	if ifMatch && errors.Is(err, ErrVersionConflict) {
+		return fmt.Errorf("%w: %v", ErrPreconditionFailed, err)
+	}
+	return err
+}
+
+// withVersion 将 If-Match 校验得到的版本号加入更新字段作为条件，updates 中已带版本号或 version 为 nil 时不处理
+func withVersion[T any](db *gorm.DB, updates map[string]interface{}, version interface{}) error {
+	if version == nil {
+		return nil
+	}
+	sch, err := parseSchema(db, new(T))
+	if err != nil {
+		return err
+	}
+	field := versionField(sch)
+	if field == nil {
+		return nil
+	}
+	for name := range updates {
+		if schemaField(sch, name) == field {
+			return nil
+		}
+	}
+	updates[field.DBName] = version
+	return nil
+}
+
+// setVersion 为 PUT 请求的记录设置版本号，version 为 nil 时不处理
+func setVersion(db *gorm.DB, entity interface{}, version interface{}) error {
+	if version == nil {
+		return nil
+	}
+	sch, err := parseSchema(db, entity)
+	if err != nil {
+		return err
+	}
+	if field := versionField(sch); field != nil {
+		return field.Set(db.Statement.Context, reflect.ValueOf(entity).Elem(), version)
+	}
+	return nil
+}
+
+// initVersion 创建时将为零的版本号设为 1，与列的默认值一致
+func initVersion(db *gorm.DB, entity interface{}) {
+	sch, err := parseSchema(db, entity)
+	if err != nil {
+		return
+	}
+	field := versionField(sch)
+	if field == nil {
+		return
+	}
+	rv := reflect.ValueOf(entity).Elem()
+	if _, isZero := field.ValueOf(db.Statement.Context, rv); isZero {
+		_ = field.Set(db.Statement.Context, rv, 1)
+	}
+}
+
+// matchedCount 带版本条件按过滤条件更新前，统计过滤条件命中的记录数，用于发现部分记录版本不一致
+func matchedCount[T any](tx *gorm.DB, filters interface{}) (int64, error) {
+	var count int64
+	err := NewQueryBuilder(tx.Model(new(T))).ApplyFilters(filters).GetDB().Count(&count).Error
+	return count, err
+}
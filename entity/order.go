@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type Order struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    string    `gorm:"type:varchar(32);index" json:"user_id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Order) TableName() string {
+	return "user_order"
+}
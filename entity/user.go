@@ -1,9 +1,21 @@
 package entity
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type User struct {
-	ID   string `gorm:"type:varchar(32);primaryKey" json:"id"`
-	Name string `json:"name"`
-	Age  int    `json:"age"`
+	ID        string         `gorm:"type:varchar(32);primaryKey" json:"id"`
+	Name      string         `json:"name" binding:"required,max=64"`
+	Age       int            `json:"age" binding:"gte=0,lte=150"`
+	CreatedAt time.Time      `json:"created_at" update:"-"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at" update:"-"` // 只读：创建时忽略请求中的值
+	Version   int64          `gorm:"not null;default:1" json:"version" version:"true"`
+	TenantID  string         `gorm:"type:varchar(32);index" json:"tenant_id" tenant:"true" update:"-"` // 只读：创建时忽略请求中的值，限定租户时由回调填充
+	// 只读关联，通过查询请求的 include 加载，每个用户最多加载 20 条
+	Orders []Order `gorm:"foreignKey:UserID;->" json:"orders,omitempty" include:"max=20"`
 }
 
 func (User) TableName() string {
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+
 	"github.com/chenfeifan111/generics_crud/config"
 	"github.com/chenfeifan111/generics_crud/controller"
 	"github.com/chenfeifan111/generics_crud/dbkit"
@@ -30,8 +32,8 @@ func main() {
 		users.POST("/one", controller.GetUserOneV2)
 
 		// 创建
-		users.POST("", controller.CreateUser)
-		users.POST("/with-transaction", controller.CreateUserWithTransaction)
+		users.POST("", controller.CreateUserV2)
+		users.POST("/native", controller.CreateUserNative)
 
 		// 更新
 		users.POST("/update", controller.UpdateUsersV2)
@@ -47,48 +49,64 @@ func main() {
 		// 统计
 		users.POST("/stats", controller.GetUserStats)
 
-		// 范围查询
-		users.POST("/range", controller.QueryUsersWithRange)
-
 		// OR 条件查询
 		users.POST("/or", controller.QueryUsersWithOr)
 	}
 
-	// ============ 方式2: 直接在路由中使用通用 Handler ============
-	usersV3 := r.Group("/users-v3")
-	{
-		// 查询（返回完整实体）
-		usersV3.POST("/query",
-			dbkit.GenericQueryHandler[entity.User, request.UserFilters, request.UserOrders](config.DB))
-
-		// 查询（返回DTO）
-		usersV3.POST("/query2",
-			dbkit.GenericQueryToHandler[entity.User, dto.UserQuery2Item, request.UserFilters, request.UserOrders](config.DB))
-
-		// 获取单条
-		usersV3.POST("/one",
-			dbkit.GenericGetOneHandler[entity.User, request.UserFilters, request.UserOrders](config.DB))
-
-		// 创建
-		usersV3.POST("",
-			dbkit.GenericCreateHandler[entity.User](config.DB))
-
-		// 更新
-		usersV3.POST("/update",
-			dbkit.GenericUpdateHandler[entity.User, request.UserFilters](config.DB))
-
-		// 删除
-		usersV3.POST("/delete",
-			dbkit.GenericDeleteHandler[entity.User, request.UserFilters](config.DB))
-
-		// 统计
-		usersV3.POST("/stats",
-			dbkit.GenericStatsHandler[entity.User, request.UserFilters, request.UserOrders](config.DB))
-
-		// 批量创建
-		usersV3.POST("/batch",
-			dbkit.GenericBatchCreateHandler[entity.User](config.DB, 100))
-	}
+	// ============ 方式2: 一行注册整套 CRUD 路由 ============
+	// 原先逐个挂载通用 Handler 的 /users-v3 路由（query / one / 创建 / update / delete / stats / batch）
+	// 现由 RegisterResource 提供，路径不变；另外挂载 GET /:id、batch-update、batch-delete 等
+	// User 有 DeletedAt 字段，删除为软删除，并额外挂载 POST /:id/restore 与 DELETE /:id/purge
+	// 开启审计：变更写入 audit_log 表，并挂载 GET /:id/history；操作人取自 X-User 请求头
+	usersV3 := dbkit.RegisterResource[entity.User, request.UserFilters, request.UserOrders, request.UserUpdates](r, "/users-v3", dbkit.ResourceOptions{
+		DB:         dbkit.EnableAudit(config.DB),
+		GenerateID: true,
+		Middlewares: []gin.HandlerFunc{dbkit.AuditMiddleware(func(c *gin.Context) string {
+			return c.GetHeader("X-User")
+		})},
+		Options: []dbkit.Option{dbkit.WithHooks(dbkit.Hooks[entity.User]{
+			// 只有管理员可以查看、恢复或永久删除已删除的用户
+			AllowDeleted: func(c *gin.Context) error {
+				if c.GetHeader("X-Role") != "admin" {
+					return dbkit.Abort(http.StatusForbidden, "admin only")
+				}
+				return nil
+			},
+		})},
+	})
+
+	// 同一资源以不同响应格式暴露：裸数组 + X-Total-Count/Link 响应头、JSON:API
+	dbkit.RegisterResource[entity.User, request.UserFilters, request.UserOrders, request.UserUpdates](r, "/users-bare", dbkit.ResourceOptions{
+		DB:      config.DB,
+		Only:    []dbkit.Route{dbkit.RouteList, dbkit.RouteGet},
+		Options: []dbkit.Option{dbkit.WithResponseWriter(dbkit.BareWriter{})},
+	})
+	dbkit.RegisterResource[entity.User, request.UserFilters, request.UserOrders, request.UserUpdates](r, "/users-jsonapi", dbkit.ResourceOptions{
+		DB:      config.DB,
+		Only:    []dbkit.Route{dbkit.RouteList, dbkit.RouteGet},
+		Options: []dbkit.Option{dbkit.WithResponseWriter(dbkit.JSONAPIWriter{Type: "users"})},
+	})
+
+	// 多租户：租户取自 X-Tenant-ID 请求头，所有读写只作用于该租户的用户，创建时自动填充 tenant_id
+	dbkit.RegisterResource[entity.User, request.UserFilters, request.UserOrders, request.UserUpdates](r, "/tenant-users", dbkit.ResourceOptions{
+		DB:         config.DB,
+		GenerateID: true,
+		Except:     []dbkit.Route{dbkit.RouteRestore, dbkit.RoutePurge},
+		Options:    []dbkit.Option{dbkit.WithTenant(dbkit.TenantFromHeader("X-Tenant-ID"))},
+	})
+
+	// 授权策略：非管理员只能读写 18 岁以上的用户，看不到 tenant_id，不能修改年龄，也不能删除
+	dbkit.RegisterResource[entity.User, request.UserFilters, request.UserOrders, request.UserUpdates](r, "/managed-users", dbkit.ResourceOptions{
+		DB:         config.DB,
+		GenerateID: true,
+		Except:     []dbkit.Route{dbkit.RouteRestore, dbkit.RoutePurge},
+		Options:    []dbkit.Option{dbkit.WithPolicy[entity.User](userPolicy{})},
+	})
+
+	// ============ 方式3: 在资源路由上直接使用通用 Handler ============
+	// 查询（返回DTO）
+	usersV3.POST("/query2",
+		dbkit.GenericQueryToHandler[entity.User, dto.UserQuery2Item, request.UserFilters, request.UserOrders](config.DB))
 
 	// ============ 分组查询示例 ============
 	groupExample := r.Group("/group-example")
@@ -102,3 +120,39 @@ func main() {
 		return
 	}
 }
+
+// userPolicy 按 X-Role 请求头授权，实际项目中身份通常由认证中间件放入 gin.Context
+type userPolicy struct {
+	dbkit.AllowAll[entity.User]
+}
+
+func (userPolicy) restricted(c *gin.Context) *dbkit.Permission {
+	if c.GetHeader("X-Role") == "admin" {
+		return nil
+	}
+	return &dbkit.Permission{
+		Scope:    dbkit.DynamicFilter{{Field: "age", Op: "gte", Value: 18}},
+		Hidden:   []string{"tenant_id"},
+		ReadOnly: []string{"age"},
+	}
+}
+
+func (p userPolicy) CanQuery(c *gin.Context) (*dbkit.Permission, error) {
+	return p.restricted(c), nil
+}
+
+// CanCreate 非管理员只能创建查询范围内（已成年）的用户，超出范围时返回 403
+func (p userPolicy) CanCreate(c *gin.Context, user *entity.User) (*dbkit.Permission, error) {
+	return p.restricted(c), nil
+}
+
+func (p userPolicy) CanUpdate(c *gin.Context, filters interface{}, updates map[string]interface{}) (*dbkit.Permission, error) {
+	return p.restricted(c), nil
+}
+
+func (userPolicy) CanDelete(c *gin.Context, filters interface{}) (*dbkit.Permission, error) {
+	if c.GetHeader("X-Role") != "admin" {
+		return nil, dbkit.Abort(http.StatusForbidden, "admin only")
+	}
+	return nil, nil
+}
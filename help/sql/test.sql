@@ -17,6 +17,24 @@
 SET NAMES utf8mb4;
 SET FOREIGN_KEY_CHECKS = 0;
 
+-- ----------------------------
+-- Table structure for audit_log
+-- ----------------------------
+DROP TABLE IF EXISTS `audit_log`;
+CREATE TABLE `audit_log`  (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+  `entity` varchar(64) CHARACTER SET utf8 COLLATE utf8_general_ci NULL DEFAULT NULL COMMENT '表名',
+  `entity_id` varchar(64) CHARACTER SET utf8 COLLATE utf8_general_ci NULL DEFAULT NULL,
+  `action` varchar(16) CHARACTER SET utf8 COLLATE utf8_general_ci NULL DEFAULT NULL COMMENT 'create/update/delete/restore/purge',
+  `before` json NULL COMMENT '变更前的快照',
+  `after` json NULL COMMENT '变更后的快照',
+  `actor` varchar(64) CHARACTER SET utf8 COLLATE utf8_general_ci NULL DEFAULT NULL COMMENT '操作人',
+  `request_id` varchar(64) CHARACTER SET utf8 COLLATE utf8_general_ci NULL DEFAULT NULL,
+  `created_at` datetime(3) NULL DEFAULT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_audit_log_entity`(`entity`, `entity_id`) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 1 CHARACTER SET = utf8 COLLATE = utf8_general_ci ROW_FORMAT = Dynamic;
+
 -- ----------------------------
 -- Table structure for group_example
 -- ----------------------------
@@ -45,16 +63,42 @@ CREATE TABLE `user`  (
   `id` varchar(32) CHARACTER SET utf8 COLLATE utf8_general_ci NOT NULL,
   `name` varchar(255) CHARACTER SET utf8 COLLATE utf8_general_ci NULL DEFAULT NULL,
   `age` int(11) NULL DEFAULT NULL,
-  PRIMARY KEY (`id`) USING BTREE
-) ENGINE = MyISAM AUTO_INCREMENT = 1 CHARACTER SET = utf8 COLLATE = utf8_general_ci ROW_FORMAT = Dynamic;
+  `created_at` datetime(3) NULL DEFAULT NULL,
+  `deleted_at` datetime(3) NULL DEFAULT NULL,
+  `version` bigint(20) NOT NULL DEFAULT 1 COMMENT '乐观锁版本号',
+  `tenant_id` varchar(32) CHARACTER SET utf8 COLLATE utf8_general_ci NOT NULL DEFAULT '' COMMENT '租户',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_user_deleted_at`(`deleted_at`) USING BTREE,
+  INDEX `idx_user_tenant_id`(`tenant_id`) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 1 CHARACTER SET = utf8 COLLATE = utf8_general_ci ROW_FORMAT = Dynamic;
 
 -- ----------------------------
 -- Records of user
 -- ----------------------------
-INSERT INTO `user` VALUES ('1', 'zs', 22);
-INSERT INTO `user` VALUES ('2', 'ls', 17);
-INSERT INTO `user` VALUES ('3', 'zss', 19);
-INSERT INTO `user` VALUES ('4', 'zsss', 22);
-INSERT INTO `user` VALUES ('5', 'ls2', 90);
+INSERT INTO `user` (`id`, `name`, `age`) VALUES ('1', 'zs', 22);
+INSERT INTO `user` (`id`, `name`, `age`) VALUES ('2', 'ls', 17);
+INSERT INTO `user` (`id`, `name`, `age`) VALUES ('3', 'zss', 19);
+INSERT INTO `user` (`id`, `name`, `age`) VALUES ('4', 'zsss', 22);
+INSERT INTO `user` (`id`, `name`, `age`) VALUES ('5', 'ls2', 90);
+
+-- ----------------------------
+-- Table structure for user_order
+-- ----------------------------
+DROP TABLE IF EXISTS `user_order`;
+CREATE TABLE `user_order`  (
+  `id` bigint(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+  `user_id` varchar(32) CHARACTER SET utf8 COLLATE utf8_general_ci NULL DEFAULT NULL,
+  `amount` double NULL DEFAULT NULL COMMENT '订单金额',
+  `created_at` datetime(3) NULL DEFAULT NULL,
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_user_order_user_id`(`user_id`) USING BTREE
+) ENGINE = InnoDB AUTO_INCREMENT = 1 CHARACTER SET = utf8 COLLATE = utf8_general_ci ROW_FORMAT = Dynamic;
+
+-- ----------------------------
+-- Records of user_order
+-- ----------------------------
+INSERT INTO `user_order` (`user_id`, `amount`) VALUES ('1', 99.5);
+INSERT INTO `user_order` (`user_id`, `amount`) VALUES ('1', 230);
+INSERT INTO `user_order` (`user_id`, `amount`) VALUES ('3', 15);
 
 SET FOREIGN_KEY_CHECKS = 1;
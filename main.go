@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"github.com/chenfeifan111/generics_crud/config"
 	"github.com/chenfeifan111/generics_crud/controller"
+	"github.com/chenfeifan111/generics_crud/dbkit"
+	"github.com/chenfeifan111/generics_crud/entity"
+	"github.com/chenfeifan111/generics_crud/request"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
@@ -34,10 +37,10 @@ func main() {
 		users.POST("/stats", controller.GetUserStats)            //统计查询
 	}
 
-	groupExample := r.Group("/group-example")
-	{
-		groupExample.POST("/group", controller.GroupQuery) //分组查询示例(自定义分组字段)
-	}
+	// 通用 CRUD 路由一行注册，返回的路由组可继续追加自定义路由
+	groupExample := dbkit.RegisterResource[entity.GroupExample, request.GroupExampleFilters, request.GroupExampleOrders, request.GroupExampleUpdates](
+		r, "/group-example", dbkit.ResourceOptions{DB: config.DB})
+	groupExample.POST("/group", controller.GroupQuery) //分组查询示例(自定义分组字段)
 
 	port := viper.GetInt("server.port")
 	err := r.Run(fmt.Sprintf(":%d", port))
@@ -1,7 +1,22 @@
 package request
 
-type GroupQueryRequest struct {
-	GroupBy []string `json:"group_by" binding:"required"` // 分组字段，如 ["department"] 或 ["department", "name"]
-	Select  []string `json:"select"`                      // 自定义查询字段，如 ["department", "COUNT(*) AS count"]
-	Having  string   `json:"having"`                      // HAVING条件，如 "COUNT(*) > 1"
+import "github.com/chenfeifan111/generics_crud/dbkit"
+
+type GroupExampleFilters struct {
+	ID         *int    `json:"id" filter:"eq"`
+	Name       *string `json:"name" filter:"like"`
+	Department *string `json:"department" filter:"eq"`
+}
+
+// GroupQueryRequest 分组查询请求：group_by 为分组字段，aggregates 为聚合函数，having 为结构化的分组过滤条件
+type GroupQueryRequest = dbkit.GroupRequest[GroupExampleFilters]
+
+type GroupExampleUpdates struct {
+	Name       *string `json:"name" binding:"omitempty,min=1"`
+	Department *string `json:"department" binding:"omitempty,min=1"`
+}
+
+type GroupExampleOrders struct {
+	ID         *string `json:"id"`
+	Department *string `json:"department"`
 }
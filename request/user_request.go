@@ -4,8 +4,15 @@ import "github.com/chenfeifan111/generics_crud/dbkit"
 
 type UserFilters struct {
 	ID   *string `json:"id" filter:"eq"`
-	Age  *int    `json:"age" filter:"gte"`
+	Age  *int    `json:"age" filter:"gte" binding:"omitempty,gte=0"`
 	Name *string `json:"name" filter:"like"`
+	// 同一列多个条件：column 覆盖列名，如 age >= 18 AND age < 65
+	AgeMax *int `json:"age_max" column:"age" filter:"lt"`
+	// 操作符映射：{"age_ops": {"gte": 18, "lt": 65}}
+	AgeOps map[string]interface{} `json:"age_ops" column:"age"`
+	// 关联字段：存在金额不小于该值的订单
+	OrderAmount *float64 `json:"order_amount" column:"orders.amount" filter:"gte"`
+	dbkit.Logic[UserFilters]
 }
 
 type UserOrders struct {
@@ -34,10 +41,15 @@ type UserUpdateByFiltersRequest struct {
 		Age  *int    `json:"age" filter:"gte"`
 		Name *string `json:"name" filter:"like"`
 	} `json:"filters"`
-	Updates struct {
-		Name *string `json:"name"`
-		Age  *int    `json:"age"`
-	} `json:"updates"`
+	Updates UserUpdates `json:"updates"`
+}
+
+// UserUpdates 用户更新字段：只更新非 nil 的字段，binding 标签在绑定请求时校验。
+// Version 为期望的版本号，只在版本一致时更新；未设置时须带 If-Match 请求头，否则返回 428
+type UserUpdates struct {
+	Name    *string `json:"name" binding:"omitempty,min=1,max=64"`
+	Age     *int    `json:"age" binding:"omitempty,gte=0,lte=150"`
+	Version *int64  `json:"version" binding:"omitempty,gte=1"`
 }
 
 type UserDeleteByFiltersRequest struct {